@@ -15,9 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/token/{tokenSymbol}/history": {
-            "get": {
-                "description": "Retrieve 1-year price history for a specific LST token",
+        "/api/admin/scoring-profiles/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Store the component weights of a composite score profile. Weights are relative and keyed by component: apr, stability, tvl, valuation, depeg, liquidity.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,28 +30,176 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tokens"
+                    "admin"
                 ],
-                "summary": "Get price history for a token",
+                "summary": "Create or replace a scoring profile",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Token symbol (e.g., wstETH, rETH)",
-                        "name": "tokenSymbol",
+                        "description": "Profile name (lowercase letters, digits and dashes)",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Profile description and weights",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ScoringProfileInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "price_history: array of price points, count: number of data points",
+                        "description": "stored profile",
+                        "schema": {
+                            "$ref": "#/definitions/services.ScoringProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid profile",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to save profile",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Remove a composite score weight profile.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a scoring profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Profile name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "profile removed"
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: profile not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to delete profile",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/tokens": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Add a Liquid Staking Token to the registry. The contract's ERC20 name, symbol and decimals are checked on-chain before the token is stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Register a token",
+                "parameters": [
+                    {
+                        "description": "Token to register",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.TokenInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "registered token",
+                        "schema": {
+                            "$ref": "#/definitions/services.Token"
                         }
                     },
                     "400": {
-                        "description": "error: invalid token symbol",
+                        "description": "error: invalid token or on-chain mismatch",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: token already exists",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -55,7 +208,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "error: failed to fetch price data",
+                        "description": "error: failed to create token",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -66,9 +219,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/token/{tokenSymbol}/valuation": {
-            "get": {
-                "description": "Retrieve APR, stability, TVL, and valuation remarks for a specific LST token",
+        "/api/admin/tokens/{tokenSymbol}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replace the registry entry of a token. The contract's ERC20 name, symbol and decimals are checked on-chain before the change is stored. Omitted is_active and is_custodial keep their stored values.",
                 "consumes": [
                     "application/json"
                 ],
@@ -76,9 +234,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tokens"
+                    "admin"
                 ],
-                "summary": "Get valuation metrics for a token",
+                "summary": "Update a token",
                 "parameters": [
                     {
                         "type": "string",
@@ -86,17 +244,53 @@ const docTemplate = `{
                         "name": "tokenSymbol",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Updated token fields",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.TokenInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "valuation metrics for the token",
+                        "description": "updated token",
                         "schema": {
-                            "$ref": "#/definitions/services.ValuationData"
+                            "$ref": "#/definitions/services.Token"
                         }
                     },
                     "400": {
-                        "description": "error: invalid token symbol",
+                        "description": "error: invalid token or on-chain mismatch",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: token not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: contract address already registered",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -105,7 +299,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "error: failed to calculate valuation",
+                        "description": "error: failed to update token",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -114,31 +308,54 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/tokens": {
-            "get": {
-                "description": "Retrieve a list of all active Liquid Staking Tokens being tracked",
-                "consumes": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
                 ],
+                "description": "Deactivate a token so it is no longer tracked. Stored price and valuation history is kept.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tokens"
+                    "admin"
+                ],
+                "summary": "Delete a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all tracked LST tokens",
                 "responses": {
-                    "200": {
-                        "description": "tokens: array of token objects, count: number of tokens",
+                    "204": {
+                        "description": "token deactivated"
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: token not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "error: error message",
+                        "description": "error: failed to delete token",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -149,9 +366,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/valuations": {
-            "get": {
-                "description": "Retrieve APR, stability, TVL, and valuation remarks for all tracked LST tokens (sortable table data)",
+        "/api/admin/tokens/{tokenSymbol}/deployments": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Add a bridged deployment of a token on another chain so its supply is included in TVL. The contract's ERC20 symbol and decimals are checked on that chain. Set escrow_address to the bridge contract on the canonical chain that locks the backing tokens so they are not counted twice.",
                 "consumes": [
                     "application/json"
                 ],
@@ -159,19 +381,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tokens"
+                    "admin"
+                ],
+                "summary": "Register a token deployment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Deployment to register",
+                        "name": "deployment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.TokenDeploymentInput"
+                        }
+                    }
                 ],
-                "summary": "Get valuation metrics for all tokens",
                 "responses": {
-                    "200": {
-                        "description": "valuations: array of valuation objects, count: number of valuations",
+                    "201": {
+                        "description": "registered deployment",
+                        "schema": {
+                            "$ref": "#/definitions/services.TokenDeployment"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid deployment or on-chain mismatch",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: token not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: deployment already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "error: failed to fetch valuations",
+                        "description": "error: failed to create deployment",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -181,34 +456,2738 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "services.ValuationData": {
-            "type": "object",
-            "properties": {
-                "apr": {
-                    "type": "number"
-                },
-                "last_updated": {
-                    "type": "string"
-                },
-                "price": {
-                    "type": "number"
+        },
+        "/api/admin/tokens/{tokenSymbol}/deployments/{blockchain}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Remove the bridged deployment of a token on a chain so its supply is no longer included in TVL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a token deployment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain of the deployment (e.g., arbitrum, optimism, base)",
+                        "name": "blockchain",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "deployment removed"
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: deployment not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to delete deployment",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/analytics/correlations": {
+            "get": {
+                "description": "Align the daily returns of the selected tokens by UTC day and compute Pearson and Spearman correlation matrices\nwith pairwise sample counts. Each pair uses only the days both tokens have a return for; pairs with fewer than 3 have null correlations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Get return correlations between tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lookback window in days, e.g. 90d (default)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated token symbols (default: all tokens)",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency of prices: eth (default), usd, eur or btc",
+                        "name": "quote",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "correlation matrices indexed like symbols",
+                        "schema": {
+                            "$ref": "#/definitions/services.CorrelationMatrix"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid window, quote currency or token symbol",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to compute correlations",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/analytics/signal-backtest": {
+            "get": {
+                "description": "Replay the valuation of each token at past dates using only the year of daily ETH prices known at each date,\nthen group the remarks and measure the 7, 30 and 90-day forward returns of each bucket. Excess returns are measured against\nthe token's average forward return over all replayed dates; a signal hits when its excess return is positive for undervalued\nand negative for overvalued remarks. Pass alternative thresholds to evaluate them before changing the live ones.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Backtest valuation signals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First valuation date, RFC3339, YYYY-MM-DD or unix seconds (default: one year before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last valuation date (default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days between valuation dates, 1-90 (default 7)",
+                        "name": "step",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated token symbols (default: all tokens)",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "APR methodology: legacy (default), point_to_point, trailing_7d, trailing_30d, trailing_90d or log_regression",
+                        "name": "apr_method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Largest deviation from the expected price rated Fair Value (default 0.001)",
+                        "name": "fair_value_tolerance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Deviation from which the Very levels apply (default 0.01)",
+                        "name": "significant_threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "forward return statistics per remark",
+                        "schema": {
+                            "$ref": "#/definitions/services.SignalBacktest"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid range, step, APR method, thresholds or token symbol",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to backtest valuation signals",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/backtest": {
+            "post": {
+                "description": "Run declarative rotation rules over stored daily ETH prices, replayed APR, stability and remarks, and the TVL and premium of stored\nvaluation snapshots. At each rebalance (daily, weekly, monthly by default, or quarterly) held tokens meeting all exit conditions are sold\nand free slots up to max_positions are filled with the tokens meeting all entry conditions, best ranked by rank_by first; without exit\nconditions holdings rotate into the top candidates. Trades execute at the daily close and pay swap_cost_bps on the traded value.\nCondition metrics: apr, stability, tvl, premium_bps (ops \u003e, \u003e=, \u003c, \u003c=, ==, != with value) and remarks (== or != with value_string, in or not_in with values).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Backtest a strategy",
+                "parameters": [
+                    {
+                        "description": "Strategy rules, universe and date range",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BacktestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "equity curve, trades and summary statistics, values in ETH",
+                        "schema": {
+                            "$ref": "#/definitions/services.StrategyBacktest"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid strategy, dates, token symbol or insufficient price data",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to run backtest",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/cache/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Invalidate and recompute cached data asynchronously. Omit symbols to refresh all tokens and omit kinds to refresh price_history, tvl and valuation. Only one refresh job runs at a time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Refresh cached data",
+                "parameters": [
+                    {
+                        "description": "Tokens and data kinds to refresh",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.RefreshCacheRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "refresh job with per-token progress",
+                        "schema": {
+                            "$ref": "#/definitions/scheduler.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid symbols or data kinds",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error: a refresh job is already running",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to start refresh",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/cache/refresh/{jobID}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Retrieve the status of a cache refresh job, including per-token progress and errors",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get cache refresh job status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Refresh job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "refresh job with per-token progress",
+                        "schema": {
+                            "$ref": "#/definitions/scheduler.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "error: unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: job not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/compare": {
+            "get": {
+                "description": "Align the price histories of the selected tokens on shared timestamps of the interval, starting at the first one every token\nhas a price, and rebase each series to 100 there. Intervals a token has no price for carry its previous price forward.\nAlso returns the price of each token in every later listed token and the current valuation of each token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Compare tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated token symbols, at least two (e.g., wstETH,rETH,CBETH)",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency of prices: eth (default), usd, eur or btc",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days ending at to; cannot be combined with from",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Granularity: hourly, daily (default) or weekly",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "APR methodology of the valuations (default legacy)",
+                        "name": "apr_method",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "aligned series, price ratios and valuations",
+                        "schema": {
+                            "$ref": "#/definitions/services.TokenComparison"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbols, quote currency, range, interval or APR method",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to compare tokens",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/portfolio/optimize": {
+            "post": {
+                "description": "Estimate annualized expected returns and covariances from the daily ETH returns of the selected tokens (default: all) over the window\n(default 365d), then solve long-only allocations capped at max_weight. Custodial tokens are dropped with exclude_custodial and tokens\nbelow min_tvl ETH of TVL are dropped. min_variance recommends the lowest-volatility allocation; mean_variance (default) maximizes\nexpected return minus risk_aversion/2 times variance, or picks the highest Sharpe ratio on the frontier when risk_aversion is omitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Optimize a portfolio allocation",
+                "parameters": [
+                    {
+                        "description": "Objective and constraints",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.PortfolioOptimizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "recommended weights and efficient frontier",
+                        "schema": {
+                            "$ref": "#/definitions/services.PortfolioOptimization"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid objective, constraints, window or token symbol",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to optimize portfolio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/portfolio/simulate": {
+            "post": {
+                "description": "Replay an allocation over the stored daily ETH prices of its tokens and return the daily portfolio value,\nblended APR, volatility and drawdown. Allocations are given either all as weights (normalized, scaled to initial_value ETH)\nor all as ETH amounts. The simulation starts on the first day every token has a price at or after start_date.\nRebalance policies: none (default), daily, weekly, monthly, quarterly, or threshold (whenever a weight drifts more than rebalance_threshold, default 0.05, from its target).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Simulate a portfolio",
+                "parameters": [
+                    {
+                        "description": "Allocation, date range and rebalancing policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PortfolioSimulationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "portfolio value series and metrics, values in ETH",
+                        "schema": {
+                            "$ref": "#/definitions/services.PortfolioSimulation"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid allocation, dates, rebalance policy or insufficient price data",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to simulate portfolio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/projection": {
+            "post": {
+                "description": "Simulate the value of amount_eth held in a token (symbol) or an allocation (weights, or ETH amounts which set the amount) over\nhorizon_days by resampling the weighted daily ETH returns of the window (default 365d): bootstrap (default) draws historical days\nwith replacement, monte_carlo draws normal returns with the historical mean and volatility. Returns the median and 5th-95th\npercentile final value, rewards and APR, the probability of loss, and value percentile bands over the horizon. Pass seed to reproduce a run.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Project staking rewards with uncertainty bands",
+                "parameters": [
+                    {
+                        "description": "Holding, amount, horizon and resampling method",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ProjectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "percentiles of projected value, rewards and APR",
+                        "schema": {
+                            "$ref": "#/definitions/services.YieldProjection"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid holding, amount, horizon, method or insufficient price data",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to project yield",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/scoring-profiles": {
+            "get": {
+                "description": "Retrieve the weight profiles that can be selected with ?profile= on /api/valuations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get scoring profiles",
+                "responses": {
+                    "200": {
+                        "description": "profiles: array of scoring profiles, count: number of profiles",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch scoring profiles",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/candles": {
+            "get": {
+                "description": "Aggregate the price history of a token into open/high/low/close buckets with point counts.\nHourly and daily candles aggregate intraday prices and cover up to 90 days (default: 7 and 90 days); weekly candles aggregate daily prices (default: 1 year).\nBuckets without data have a zero count and null prices; buckets cut by the range or still in progress are marked partial.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get OHLC candles for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket size: 1h, 1d (default) or 1w",
+                        "name": "resolution",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency of prices: eth (default), usd, eur or btc",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days ending at to; cannot be combined with from",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "candles: array of candles, count: number of candles",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol, resolution, quote currency or range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch price data",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/exchange-rate": {
+            "get": {
+                "description": "Read the token's redemption rate (underlying ETH per token) from its staking protocol contract, with the trailing 30-day protocol APR when the RPC node serves historical state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get protocol exchange rate for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "protocol exchange rate for the token",
+                        "schema": {
+                            "$ref": "#/definitions/services.ExchangeRateData"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: no exchange rate adapter for token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch exchange rate",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/history": {
+            "get": {
+                "description": "Retrieve price history for a specific LST token over a range (default: last year, daily).\nDaily and weekly history is served from stored prices; hourly history covers at most 90 days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get price history for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency of prices: eth (default), usd, eur or btc",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days ending at to; cannot be combined with from",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Granularity: hourly, daily (default) or weekly",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "price_history: array of price points, count: number of data points",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol, quote currency, range or interval",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch price data",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/premium/history": {
+            "get": {
+                "description": "Retrieve the market price, protocol exchange rate and premium in basis points (negative for a discount) from stored valuation snapshots",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get premium/discount to NAV history for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "premium_history: array of premium points, count: number of points",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol or time range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error: no exchange rate adapter for token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch premium history",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/risk": {
+            "get": {
+                "description": "Compute annualized volatility, max drawdown and its duration, downside deviation, Sharpe and Sortino ratios\nagainst a base staking rate, and historical 95% VaR/CVaR from the daily price history (default: last year).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get risk metrics for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency of prices: eth (default), usd, eur or btc",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days ending at to; cannot be combined with from",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Annual base staking rate as a fraction (default: BASE_STAKING_RATE or 0.03)",
+                        "name": "base_rate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "risk metrics for the token",
+                        "schema": {
+                            "$ref": "#/definitions/services.RiskMetrics"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol, quote currency, range or base rate, or insufficient price data",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to compute risk metrics",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/tvl": {
+            "get": {
+                "description": "Retrieve the token supply on its canonical chain and every registered bridged deployment. Canonical tokens locked in bridge escrows are excluded so bridged supply is not counted twice.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get TVL for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "total TVL and supply per chain",
+                        "schema": {
+                            "$ref": "#/definitions/services.TVLData"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch TVL",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/valuation": {
+            "get": {
+                "description": "Retrieve APR, stability, TVL, and valuation remarks for a specific LST token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get valuation metrics for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency of price and TVL: eth (default), usd, eur or btc",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "APR methodology: legacy (default), point_to_point, trailing_7d, trailing_30d, trailing_90d, log_regression or protocol_rate",
+                        "name": "apr_method",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "valuation metrics for the token",
+                        "schema": {
+                            "$ref": "#/definitions/services.ValuationData"
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol, quote currency or APR method",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to calculate valuation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "error: valuation not computed yet",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/{tokenSymbol}/valuation/history": {
+            "get": {
+                "description": "Retrieve timestamped valuation snapshots (price, APR, stability, TVL, remarks) for a specific LST token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get valuation history for a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token symbol (e.g., wstETH, rETH)",
+                        "name": "tokenSymbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "valuation_history: array of valuation snapshots, count: number of snapshots",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid token symbol or time range",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch valuation history",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tokens": {
+            "get": {
+                "description": "Retrieve a list of all active Liquid Staking Tokens being tracked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get all tracked LST tokens",
+                "responses": {
+                    "200": {
+                        "description": "tokens: array of token objects, count: number of tokens",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error: error message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/valuations": {
+            "get": {
+                "description": "Retrieve APR, stability, TVL, and valuation remarks for all tracked LST tokens (sortable table data)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Get valuation metrics for all tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Quote currency of price and TVL: eth (default), usd, eur or btc",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "APR methodology: legacy (default), point_to_point, trailing_7d, trailing_30d, trailing_90d, log_regression or protocol_rate (tokens without a protocol APR fall back to legacy)",
+                        "name": "apr_method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Scoring profile (e.g. balanced, conservative, yield-seeking); adds a composite score with its breakdown and sorts by it",
+                        "name": "profile",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "valuations: array of valuation objects, count: number of valuations",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "error: invalid quote currency, APR method or scoring profile",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error: failed to fetch valuations",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.BacktestRequest": {
+            "type": "object",
+            "properties": {
+                "apr_method": {
+                    "type": "string",
+                    "example": "legacy"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "entry": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.StrategyCondition"
+                    }
+                },
+                "exit": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.StrategyCondition"
+                    }
+                },
+                "initial_value": {
+                    "type": "number",
+                    "example": 1
+                },
+                "max_positions": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rank_by": {
+                    "type": "string",
+                    "example": "apr"
+                },
+                "rank_order": {
+                    "type": "string",
+                    "example": "desc"
+                },
+                "rebalance": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "swap_cost_bps": {
+                    "type": "number",
+                    "example": 10
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "wstETH",
+                        "rETH",
+                        "cbETH"
+                    ]
+                }
+            }
+        },
+        "api.PortfolioOptimizationRequest": {
+            "type": "object",
+            "properties": {
+                "exclude_custodial": {
+                    "type": "boolean"
+                },
+                "frontier_points": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "max_weight": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "min_tvl": {
+                    "type": "number",
+                    "example": 10000
+                },
+                "objective": {
+                    "type": "string",
+                    "example": "mean_variance"
+                },
+                "risk_aversion": {
+                    "type": "number",
+                    "example": 10
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "wstETH",
+                        "rETH",
+                        "cbETH"
+                    ]
+                },
+                "window": {
+                    "type": "string",
+                    "example": "365d"
+                }
+            }
+        },
+        "api.PortfolioSimulationRequest": {
+            "type": "object",
+            "properties": {
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PortfolioAllocation"
+                    }
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-12-31"
+                },
+                "initial_value": {
+                    "type": "number",
+                    "example": 1
+                },
+                "rebalance": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "rebalance_threshold": {
+                    "type": "number",
+                    "example": 0.05
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                }
+            }
+        },
+        "api.ProjectionRequest": {
+            "type": "object",
+            "properties": {
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PortfolioAllocation"
+                    }
+                },
+                "amount_eth": {
+                    "type": "number",
+                    "example": 10
+                },
+                "horizon_days": {
+                    "type": "integer",
+                    "example": 365
+                },
+                "method": {
+                    "type": "string",
+                    "example": "bootstrap"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "simulations": {
+                    "type": "integer",
+                    "example": 5000
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "wstETH"
+                },
+                "window": {
+                    "type": "string",
+                    "example": "365d"
+                }
+            }
+        },
+        "api.RefreshCacheRequest": {
+            "type": "object",
+            "properties": {
+                "kinds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "price_history",
+                        "tvl",
+                        "valuation"
+                    ]
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "wstETH",
+                        "rETH"
+                    ]
+                }
+            }
+        },
+        "scheduler.DataKind": {
+            "type": "string",
+            "enum": [
+                "price_history",
+                "tvl",
+                "valuation"
+            ],
+            "x-enum-varnames": [
+                "PriceHistory",
+                "TVL",
+                "Valuation"
+            ]
+        },
+        "scheduler.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "kinds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.DataKind"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/scheduler.JobStatus"
+                },
+                "tokens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.TokenProgress"
+                    }
+                }
+            }
+        },
+        "scheduler.JobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "JobPending",
+                "JobRunning",
+                "JobCompleted",
+                "JobFailed"
+            ]
+        },
+        "scheduler.TokenProgress": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.DataKind"
+                    }
+                },
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/scheduler.JobStatus"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Allocation": {
+            "type": "object",
+            "properties": {
+                "expected_return": {
+                    "type": "number"
+                },
+                "risk_aversion": {
+                    "description": "Null for the minimum-variance allocation",
+                    "type": "number"
+                },
+                "sharpe_ratio": {
+                    "type": "number"
+                },
+                "volatility": {
+                    "type": "number"
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "services.ChainTVL": {
+            "type": "object",
+            "properties": {
+                "blockchain": {
+                    "type": "string"
+                },
+                "canonical": {
+                    "type": "boolean"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Set when the chain could not be read; excluded from TVL",
+                    "type": "string"
+                },
+                "supply": {
+                    "type": "number"
+                },
+                "tvl": {
+                    "description": "Supply valued in ETH",
+                    "type": "number"
+                }
+            }
+        },
+        "services.ComparisonSeries": {
+            "type": "object",
+            "properties": {
+                "filled": {
+                    "description": "Intervals carried forward from an earlier price",
+                    "type": "integer"
+                },
+                "prices": {
+                    "description": "In the quote currency",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "rebased": {
+                    "description": "100 at the first timestamp",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.CompositeScore": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ScoreComponent"
+                    }
+                },
+                "profile": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.CorrelationMatrix": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "pearson": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "samples": {
+                    "description": "Aligned daily returns behind each pair",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "spearman": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ExchangeRateData": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer"
+                },
+                "last_updated": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "protocol_apr": {
+                    "description": "Trailing 30-day annualized rate growth",
+                    "type": "number"
+                },
+                "rate": {
+                    "description": "Underlying ETH per token",
+                    "type": "number"
+                },
+                "token_symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.HorizonStats": {
+            "type": "object",
+            "properties": {
+                "avg_excess_return": {
+                    "type": "number"
+                },
+                "avg_return": {
+                    "type": "number"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "hit_rate": {
+                    "description": "Null for Fair Value and Unknown, which predict no direction",
+                    "type": "number"
+                },
+                "samples": {
+                    "description": "Signals with a price at the end of the horizon",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.PortfolioAllocation": {
+            "type": "object",
+            "properties": {
+                "amount_eth": {
+                    "type": "number",
+                    "example": 10
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "wstETH"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 0.5
+                }
+            }
+        },
+        "services.PortfolioHolding": {
+            "type": "object",
+            "properties": {
+                "apr": {
+                    "description": "Annualized point-to-point return over the simulation",
+                    "type": "number"
+                },
+                "final_value": {
+                    "description": "In ETH",
+                    "type": "number"
+                },
+                "final_weight": {
+                    "type": "number"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "target_weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.PortfolioOptimization": {
+            "type": "object",
+            "properties": {
+                "base_rate": {
+                    "description": "Annual rate Sharpe ratios are measured against",
+                    "type": "number"
+                },
+                "excluded": {
+                    "description": "Reason per excluded token",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "expected_returns": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "frontier": {
+                    "description": "Ordered by volatility",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Allocation"
+                    }
+                },
+                "max_weight": {
+                    "type": "number"
+                },
+                "objective": {
+                    "type": "string"
+                },
+                "observations": {
+                    "description": "Aligned daily returns",
+                    "type": "integer"
+                },
+                "recommended": {
+                    "$ref": "#/definitions/services.Allocation"
+                },
+                "symbols": {
+                    "description": "Eligible tokens",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "volatilities": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "services.PortfolioPoint": {
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "In ETH",
+                    "type": "number"
+                }
+            }
+        },
+        "services.PortfolioSimulation": {
+            "type": "object",
+            "properties": {
+                "blended_apr": {
+                    "description": "Target-weighted average of the token APRs",
+                    "type": "number"
+                },
+                "final_value": {
+                    "description": "In ETH",
+                    "type": "number"
+                },
+                "from": {
+                    "description": "First day all tokens have a price",
+                    "type": "string"
+                },
+                "holdings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PortfolioHolding"
+                    }
+                },
+                "initial_value": {
+                    "description": "In ETH",
+                    "type": "number"
+                },
+                "rebalance": {
+                    "type": "string"
+                },
+                "rebalances": {
+                    "type": "integer"
+                },
+                "risk": {
+                    "description": "Volatility, drawdown and ratios of the portfolio value",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.RiskMetrics"
+                        }
+                    ]
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PortfolioPoint"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total_return": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.PriceRatio": {
+            "type": "object",
+            "properties": {
+                "priced_in": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "services.ProjectionBand": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "In ETH",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.ProjectionPercentiles"
+                        }
+                    ]
+                }
+            }
+        },
+        "services.ProjectionPercentiles": {
+            "type": "object",
+            "properties": {
+                "p25": {
+                    "type": "number"
+                },
+                "p5": {
+                    "type": "number"
+                },
+                "p50": {
+                    "description": "Median",
+                    "type": "number"
+                },
+                "p75": {
+                    "type": "number"
+                },
+                "p95": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.RemarkThresholds": {
+            "type": "object",
+            "properties": {
+                "fair_value_tolerance": {
+                    "description": "Largest deviation still rated Fair Value",
+                    "type": "number"
+                },
+                "significant_threshold": {
+                    "description": "Deviation from which the \"Very\" levels apply",
+                    "type": "number"
+                }
+            }
+        },
+        "services.RiskMetrics": {
+            "type": "object",
+            "properties": {
+                "annualized_return": {
+                    "type": "number"
+                },
+                "annualized_volatility": {
+                    "type": "number"
+                },
+                "base_rate": {
+                    "description": "Annual rate excess returns are measured against",
+                    "type": "number"
+                },
+                "cvar_95": {
+                    "type": "number"
+                },
+                "downside_deviation": {
+                    "description": "Annualized, below the daily base rate",
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "last_updated": {
+                    "type": "string"
+                },
+                "max_drawdown": {
+                    "type": "number"
+                },
+                "max_drawdown_days": {
+                    "description": "From the peak until recovery or the end of the range",
+                    "type": "number"
+                },
+                "max_drawdown_peak": {
+                    "description": "Timestamp in milliseconds",
+                    "type": "integer"
+                },
+                "max_drawdown_recovered": {
+                    "type": "boolean"
+                },
+                "max_drawdown_trough": {
+                    "description": "Timestamp in milliseconds",
+                    "type": "integer"
+                },
+                "observations": {
+                    "description": "Number of daily returns",
+                    "type": "integer"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "sharpe_ratio": {
+                    "type": "number"
+                },
+                "sortino_ratio": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "token_symbol": {
+                    "type": "string"
+                },
+                "var_95": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.ScoreComponent": {
+            "type": "object",
+            "properties": {
+                "contribution": {
+                    "description": "Points added to the score",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "normalized": {
+                    "description": "Input scaled to 0-1, higher is better",
+                    "type": "number"
+                },
+                "value": {
+                    "description": "Raw input, nil when unavailable",
+                    "type": "number"
+                },
+                "weight": {
+                    "description": "Weight in the profile",
+                    "type": "number"
+                }
+            }
+        },
+        "services.ScoringProfile": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "services.ScoringProfileInput": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Favors stable, large, liquid tokens"
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "services.SignalBacktest": {
+            "type": "object",
+            "properties": {
+                "apr_method": {
+                    "type": "string"
+                },
+                "baselines": {
+                    "description": "Average forward return per token and horizon",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SignalBucket"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "horizons": {
+                    "description": "Forward windows in days",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "signals": {
+                    "description": "Replayed valuations across all tokens",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "Dates per token without enough history for a valuation",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "step_days": {
+                    "type": "integer"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "thresholds": {
+                    "$ref": "#/definitions/services.RemarkThresholds"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.SignalBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "horizons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.HorizonStats"
+                    }
+                },
+                "remarks": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Strategy": {
+            "type": "object",
+            "properties": {
+                "apr_method": {
+                    "type": "string",
+                    "example": "legacy"
+                },
+                "entry": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.StrategyCondition"
+                    }
+                },
+                "exit": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.StrategyCondition"
+                    }
+                },
+                "max_positions": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rank_by": {
+                    "type": "string",
+                    "example": "apr"
+                },
+                "rank_order": {
+                    "type": "string",
+                    "example": "desc"
+                },
+                "rebalance": {
+                    "type": "string",
+                    "example": "monthly"
+                },
+                "swap_cost_bps": {
+                    "type": "number",
+                    "example": 10
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "wstETH",
+                        "rETH",
+                        "cbETH"
+                    ]
+                }
+            }
+        },
+        "services.StrategyBacktest": {
+            "type": "object",
+            "properties": {
+                "benchmark_return": {
+                    "description": "Equal-weight buy and hold of the tokens priced on the first day",
+                    "type": "number"
+                },
+                "equity_curve": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PortfolioPoint"
+                    }
+                },
+                "exposure": {
+                    "description": "Share of days holding at least one token",
+                    "type": "number"
+                },
+                "final_value": {
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "holdings": {
+                    "description": "Tokens held at the end",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "initial_value": {
+                    "type": "number"
+                },
+                "rebalances": {
+                    "description": "Dates the rules were evaluated",
+                    "type": "integer"
+                },
+                "risk": {
+                    "$ref": "#/definitions/services.RiskMetrics"
+                },
+                "strategy": {
+                    "description": "With defaults applied",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.Strategy"
+                        }
+                    ]
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total_costs": {
+                    "type": "number"
+                },
+                "total_return": {
+                    "type": "number"
+                },
+                "trade_count": {
+                    "type": "integer"
+                },
+                "traded_value": {
+                    "type": "number"
+                },
+                "trades": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.StrategyTrade"
+                    }
+                }
+            }
+        },
+        "services.StrategyCondition": {
+            "type": "object",
+            "properties": {
+                "metric": {
+                    "type": "string",
+                    "example": "remarks"
+                },
+                "op": {
+                    "type": "string",
+                    "example": "in"
+                },
+                "value": {
+                    "type": "number"
+                },
+                "value_string": {
+                    "type": "string"
+                },
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Undervalued",
+                        "Very Undervalued"
+                    ]
+                }
+            }
+        },
+        "services.StrategyTrade": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "description": "Swap cost in ETH",
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "side": {
+                    "description": "buy or sell",
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "units": {
+                    "type": "number"
+                },
+                "value": {
+                    "description": "In ETH",
+                    "type": "number"
+                }
+            }
+        },
+        "services.TVLData": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "description": "Supply per chain; Supply is the sum of the chains that could be read",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ChainTVL"
+                    }
+                },
+                "last_updated": {
+                    "type": "string"
+                },
+                "priced_with": {
+                    "description": "exchange_rate or market_price",
+                    "type": "string"
+                },
+                "supply": {
+                    "description": "Raw token supply across all chains",
+                    "type": "number"
+                },
+                "token_symbol": {
+                    "type": "string"
+                },
+                "tvl": {
+                    "description": "Supply valued in ETH",
+                    "type": "number"
+                },
+                "tvl_usd": {
+                    "description": "Supply valued in USD, omitted when no ETH/USD price is available",
+                    "type": "number"
+                }
+            }
+        },
+        "services.Token": {
+            "type": "object",
+            "properties": {
+                "blockchain": {
+                    "type": "string"
+                },
+                "coingecko_id": {
+                    "type": "string"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "decimals": {
+                    "type": "integer"
+                },
+                "deployments": {
+                    "description": "Bridged deployments on chains other than the canonical Blockchain",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.TokenDeployment"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "is_custodial": {
+                    "description": "Staking is run by a centralized custodian",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.TokenComparison": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "First timestamp every token has a price",
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "ratios": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PriceRatio"
+                    }
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ComparisonSeries"
+                    }
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "timestamps": {
+                    "description": "Interval starts in milliseconds, shared by every series",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "valuations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ValuationData"
+                    }
+                }
+            }
+        },
+        "services.TokenDeployment": {
+            "type": "object",
+            "properties": {
+                "blockchain": {
+                    "type": "string"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "decimals": {
+                    "type": "integer"
+                },
+                "escrow_address": {
+                    "description": "Bridge escrow on the canonical chain locking the backing tokens",
+                    "type": "string"
+                }
+            }
+        },
+        "services.TokenDeploymentInput": {
+            "type": "object",
+            "properties": {
+                "blockchain": {
+                    "type": "string"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "decimals": {
+                    "type": "integer"
+                },
+                "escrow_address": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.TokenInput": {
+            "type": "object",
+            "properties": {
+                "blockchain": {
+                    "type": "string"
+                },
+                "coingecko_id": {
+                    "type": "string"
+                },
+                "contract_address": {
+                    "type": "string"
+                },
+                "decimals": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "is_custodial": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ValuationData": {
+            "type": "object",
+            "properties": {
+                "apr": {
+                    "type": "number"
+                },
+                "apr_method": {
+                    "description": "Methodology APR was computed with",
+                    "type": "string"
+                },
+                "exchange_rate": {
+                    "description": "On-chain redemption rate and the metrics derived from it, set only for tokens with a protocol adapter",
+                    "type": "number"
+                },
+                "last_updated": {
+                    "type": "string"
+                },
+                "premium_bps": {
+                    "description": "Market premium (+) or discount (-) to the redemption rate",
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "protocol_apr": {
+                    "type": "number"
+                },
+                "quote": {
+                    "description": "Currency of price and tvl",
+                    "type": "string"
                 },
                 "remarks": {
                     "type": "string"
                 },
+                "score": {
+                    "description": "Composite score under the requested scoring profile; only set when a profile is selected",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.CompositeScore"
+                        }
+                    ]
+                },
                 "stability": {
                     "type": "number"
                 },
+                "supply": {
+                    "description": "Raw token supply across all chains",
+                    "type": "number"
+                },
                 "token_symbol": {
                     "type": "string"
                 },
                 "tvl": {
+                    "description": "Supply valued in the quote currency",
+                    "type": "number"
+                },
+                "tvl_chains": {
+                    "description": "Supply per chain behind TVL; only set on freshly computed valuations, not on stored snapshots",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ChainTVL"
+                    }
+                },
+                "tvl_usd": {
+                    "description": "Supply valued in USD",
+                    "type": "number"
+                },
+                "volume_24h": {
+                    "description": "24h trading volume in USD",
                     "type": "number"
                 }
             }
+        },
+        "services.YieldProjection": {
+            "type": "object",
+            "properties": {
+                "amount_eth": {
+                    "type": "number"
+                },
+                "apr": {
+                    "$ref": "#/definitions/services.ProjectionPercentiles"
+                },
+                "bands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ProjectionBand"
+                    }
+                },
+                "final_value": {
+                    "$ref": "#/definitions/services.ProjectionPercentiles"
+                },
+                "historical_apr": {
+                    "type": "number"
+                },
+                "historical_volatility": {
+                    "description": "Annualized",
+                    "type": "number"
+                },
+                "history_from": {
+                    "type": "string"
+                },
+                "history_to": {
+                    "type": "string"
+                },
+                "horizon_days": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "observations": {
+                    "description": "Historical daily returns resampled",
+                    "type": "integer"
+                },
+                "probability_of_loss": {
+                    "type": "number"
+                },
+                "rewards": {
+                    "$ref": "#/definitions/services.ProjectionPercentiles"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "simulations": {
+                    "type": "integer"
+                },
+                "weights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin API token, sent as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
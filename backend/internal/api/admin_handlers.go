@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateTokenHandler registers a new token after validating it on-chain
+//
+// @Summary Register a token
+// @Description Add a Liquid Staking Token to the registry. The contract's ERC20 name, symbol and decimals are checked on-chain before the token is stored.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param token body services.TokenInput true "Token to register"
+// @Success 201 {object} services.Token "registered token"
+// @Failure 400 {object} map[string]string "error: invalid token or on-chain mismatch"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 409 {object} map[string]string "error: token already exists"
+// @Failure 500 {object} map[string]string "error: failed to create token"
+// @Router /api/admin/tokens [post]
+func (h *Handler) CreateTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input services.TokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.tokenService.CreateToken(r.Context(), input)
+	if err != nil {
+		writeTokenError(w, "create", input.Symbol, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(token)
+}
+
+// UpdateTokenHandler updates a registered token after validating it on-chain
+//
+// @Summary Update a token
+// @Description Replace the registry entry of a token. The contract's ERC20 name, symbol and decimals are checked on-chain before the change is stored. Omitted is_active and is_custodial keep their stored values.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param token body services.TokenInput true "Updated token fields"
+// @Success 200 {object} services.Token "updated token"
+// @Failure 400 {object} map[string]string "error: invalid token or on-chain mismatch"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: token not found"
+// @Failure 409 {object} map[string]string "error: contract address already registered"
+// @Failure 500 {object} map[string]string "error: failed to update token"
+// @Router /api/admin/tokens/{tokenSymbol} [put]
+func (h *Handler) UpdateTokenHandler(w http.ResponseWriter, r *http.Request) {
+	tokenSymbol := chi.URLParam(r, "id")
+
+	var input services.TokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.tokenService.UpdateToken(r.Context(), tokenSymbol, input)
+	if err != nil {
+		writeTokenError(w, "update", tokenSymbol, err)
+		return
+	}
+
+	JSONResponse(w, token)
+}
+
+// DeleteTokenHandler deactivates a registered token
+//
+// @Summary Delete a token
+// @Description Deactivate a token so it is no longer tracked. Stored price and valuation history is kept.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Success 204 "token deactivated"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: token not found"
+// @Failure 500 {object} map[string]string "error: failed to delete token"
+// @Router /api/admin/tokens/{tokenSymbol} [delete]
+func (h *Handler) DeleteTokenHandler(w http.ResponseWriter, r *http.Request) {
+	tokenSymbol := chi.URLParam(r, "id")
+
+	if err := h.tokenService.DeleteToken(r.Context(), tokenSymbol); err != nil {
+		writeTokenError(w, "delete", tokenSymbol, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateTokenDeploymentHandler registers a bridged deployment of a token after validating it on-chain
+//
+// @Summary Register a token deployment
+// @Description Add a bridged deployment of a token on another chain so its supply is included in TVL. The contract's ERC20 symbol and decimals are checked on that chain. Set escrow_address to the bridge contract on the canonical chain that locks the backing tokens so they are not counted twice.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param deployment body services.TokenDeploymentInput true "Deployment to register"
+// @Success 201 {object} services.TokenDeployment "registered deployment"
+// @Failure 400 {object} map[string]string "error: invalid deployment or on-chain mismatch"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: token not found"
+// @Failure 409 {object} map[string]string "error: deployment already exists"
+// @Failure 500 {object} map[string]string "error: failed to create deployment"
+// @Router /api/admin/tokens/{tokenSymbol}/deployments [post]
+func (h *Handler) CreateTokenDeploymentHandler(w http.ResponseWriter, r *http.Request) {
+	tokenSymbol := chi.URLParam(r, "id")
+
+	var input services.TokenDeploymentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	deployment, err := h.tokenService.CreateTokenDeployment(r.Context(), tokenSymbol, input)
+	if err != nil {
+		writeTokenError(w, "create deployment for", tokenSymbol, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(deployment)
+}
+
+// DeleteTokenDeploymentHandler removes a bridged deployment of a token
+//
+// @Summary Delete a token deployment
+// @Description Remove the bridged deployment of a token on a chain so its supply is no longer included in TVL.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param blockchain path string true "Chain of the deployment (e.g., arbitrum, optimism, base)"
+// @Success 204 "deployment removed"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: deployment not found"
+// @Failure 500 {object} map[string]string "error: failed to delete deployment"
+// @Router /api/admin/tokens/{tokenSymbol}/deployments/{blockchain} [delete]
+func (h *Handler) DeleteTokenDeploymentHandler(w http.ResponseWriter, r *http.Request) {
+	tokenSymbol := chi.URLParam(r, "id")
+	blockchain := chi.URLParam(r, "blockchain")
+
+	if err := h.tokenService.DeleteTokenDeployment(r.Context(), tokenSymbol, blockchain); err != nil {
+		writeTokenError(w, "delete deployment for", tokenSymbol, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SaveScoringProfileHandler creates or replaces a composite score weight profile
+//
+// @Summary Create or replace a scoring profile
+// @Description Store the component weights of a composite score profile. Weights are relative and keyed by component: apr, stability, tvl, valuation, depeg, liquidity.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param name path string true "Profile name (lowercase letters, digits and dashes)"
+// @Param profile body services.ScoringProfileInput true "Profile description and weights"
+// @Success 200 {object} services.ScoringProfile "stored profile"
+// @Failure 400 {object} map[string]string "error: invalid profile"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 500 {object} map[string]string "error: failed to save profile"
+// @Router /api/admin/scoring-profiles/{name} [put]
+func (h *Handler) SaveScoringProfileHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	var input services.ScoringProfileInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.scoringService.SaveProfile(r.Context(), name, input)
+	if errors.Is(err, services.ErrInvalidScoringProfile) {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error trying to save scoring profile %s: %v", name, err)
+		JSONError(w, "Failed to save scoring profile", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, profile)
+}
+
+// DeleteScoringProfileHandler removes a composite score weight profile
+//
+// @Summary Delete a scoring profile
+// @Description Remove a composite score weight profile.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param name path string true "Profile name"
+// @Success 204 "profile removed"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: profile not found"
+// @Failure 500 {object} map[string]string "error: failed to delete profile"
+// @Router /api/admin/scoring-profiles/{name} [delete]
+func (h *Handler) DeleteScoringProfileHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	err := h.scoringService.DeleteProfile(r.Context(), name)
+	if errors.Is(err, services.ErrScoringProfileNotFound) {
+		JSONError(w, "Scoring profile not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error trying to delete scoring profile %s: %v", name, err)
+		JSONError(w, "Failed to delete scoring profile", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTokenError maps token registry errors to HTTP responses
+func writeTokenError(w http.ResponseWriter, action, symbol string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidToken):
+		JSONError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTokenNotFound):
+		JSONError(w, "Token not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrTokenExists):
+		JSONError(w, "Token with this symbol or contract address already exists", http.StatusConflict)
+	case errors.Is(err, services.ErrDeploymentNotFound):
+		JSONError(w, "Token deployment not found", http.StatusNotFound)
+	case errors.Is(err, services.ErrDeploymentExists):
+		JSONError(w, "Token already has a deployment on this chain or the contract address is registered", http.StatusConflict)
+	default:
+		log.Printf("Error trying to %s token %s: %v", action, symbol, err)
+		JSONError(w, "Failed to "+action+" token", http.StatusInternalServerError)
+	}
+}
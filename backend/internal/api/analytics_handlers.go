@@ -0,0 +1,243 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+)
+
+// GetCorrelationsHandler returns the correlation matrices of daily token returns
+//
+// @Summary Get return correlations between tokens
+// @Description Align the daily returns of the selected tokens by UTC day and compute Pearson and Spearman correlation matrices
+// @Description with pairwise sample counts. Each pair uses only the days both tokens have a return for; pairs with fewer than 3 have null correlations.
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param window query string false "Lookback window in days, e.g. 90d (default)"
+// @Param symbols query string false "Comma-separated token symbols (default: all tokens)"
+// @Param quote query string false "Quote currency of prices: eth (default), usd, eur or btc"
+// @Success 200 {object} services.CorrelationMatrix "correlation matrices indexed like symbols"
+// @Failure 400 {object} map[string]string "error: invalid window, quote currency or token symbol"
+// @Failure 500 {object} map[string]string "error: failed to compute correlations"
+// @Router /api/analytics/correlations [get]
+func (h *Handler) GetCorrelationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	window := 90 * 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := services.ParseWindow(windowStr)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+
+	quote, err := services.ParseQuote(r.URL.Query().Get("quote"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	symbols, ok := h.parseSymbolsParam(w, r)
+	if !ok {
+		return
+	}
+	if len(symbols) < 2 {
+		JSONError(w, "At least two tokens are required", http.StatusBadRequest)
+		return
+	}
+
+	to := time.Now().UTC()
+	query := services.HistoryQuery{
+		From:     to.Add(-window),
+		To:       to,
+		Interval: services.IntervalDaily,
+		Quote:    quote,
+	}
+
+	correlations, err := h.valuationService.GetReturnCorrelations(r.Context(), symbols, query)
+	if err != nil {
+		log.Printf("Error computing correlations for %s: %v", strings.Join(symbols, ","), err)
+		JSONError(w, "Failed to compute correlations", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, correlations)
+}
+
+// GetSignalBacktestHandler reports how well past valuation remarks predicted forward returns
+//
+// @Summary Backtest valuation signals
+// @Description Replay the valuation of each token at past dates using only the year of daily ETH prices known at each date,
+// @Description then group the remarks and measure the 7, 30 and 90-day forward returns of each bucket. Excess returns are measured against
+// @Description the token's average forward return over all replayed dates; a signal hits when its excess return is positive for undervalued
+// @Description and negative for overvalued remarks. Pass alternative thresholds to evaluate them before changing the live ones.
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param from query string false "First valuation date, RFC3339, YYYY-MM-DD or unix seconds (default: one year before to)"
+// @Param to query string false "Last valuation date (default: now)"
+// @Param step query int false "Days between valuation dates, 1-90 (default 7)"
+// @Param symbols query string false "Comma-separated token symbols (default: all tokens)"
+// @Param apr_method query string false "APR methodology: legacy (default), point_to_point, trailing_7d, trailing_30d, trailing_90d or log_regression"
+// @Param fair_value_tolerance query number false "Largest deviation from the expected price rated Fair Value (default 0.001)"
+// @Param significant_threshold query number false "Deviation from which the Very levels apply (default 0.01)"
+// @Success 200 {object} services.SignalBacktest "forward return statistics per remark"
+// @Failure 400 {object} map[string]string "error: invalid range, step, APR method, thresholds or token symbol"
+// @Failure 500 {object} map[string]string "error: failed to backtest valuation signals"
+// @Router /api/analytics/signal-backtest [get]
+func (h *Handler) GetSignalBacktestHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	from, to, err := parseTimeRange(r, 365*24*time.Hour)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	step := 7
+	if stepStr := r.URL.Query().Get("step"); stepStr != "" {
+		if step, err = strconv.Atoi(stepStr); err != nil {
+			JSONError(w, "invalid step: expected a number of days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	aprMethod, err := services.ParseAPRMethod(r.URL.Query().Get("apr_method"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	thresholds := services.DefaultRemarkThresholds
+	if thresholds.FairValueTolerance, err = parseFloatParam(r, "fair_value_tolerance", thresholds.FairValueTolerance); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if thresholds.SignificantThreshold, err = parseFloatParam(r, "significant_threshold", thresholds.SignificantThreshold); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	symbols, ok := h.parseSymbolsParam(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.valuationService.BacktestValuationSignals(r.Context(), symbols, services.SignalBacktestQuery{
+		From:       from,
+		To:         to,
+		StepDays:   step,
+		APRMethod:  aprMethod,
+		Thresholds: thresholds,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSignalBacktest) || errors.Is(err, services.ErrUnsupportedAPRMethod) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error backtesting valuation signals for %s: %v", strings.Join(symbols, ","), err)
+		JSONError(w, "Failed to backtest valuation signals", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, report)
+}
+
+// parseSymbolsParam reads the comma-separated symbols query parameter, defaulting to every active token.
+// Symbols are validated, deduplicated and returned in their registered case; on failure an error
+// response has been written and false is returned.
+func (h *Handler) parseSymbolsParam(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	tokens, ok := h.parseTokensParam(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	symbols := make([]string, len(tokens))
+	for i, token := range tokens {
+		symbols[i] = token.Symbol
+	}
+	return symbols, true
+}
+
+// parseTokensParam is parseSymbolsParam returning the tokens themselves
+func (h *Handler) parseTokensParam(w http.ResponseWriter, r *http.Request) ([]services.Token, bool) {
+	var symbols []string
+	if symbolsParam := r.URL.Query().Get("symbols"); symbolsParam != "" {
+		symbols = strings.Split(symbolsParam, ",")
+	}
+	return h.parseTokens(w, r, symbols)
+}
+
+// parseTokens resolves symbols given in a request body as parseTokensParam does for the symbols parameter
+func (h *Handler) parseTokens(w http.ResponseWriter, r *http.Request, symbols []string) ([]services.Token, bool) {
+	if len(symbols) == 0 {
+		tokens, err := h.tokenService.GetAllTokens(r.Context())
+		if err != nil {
+			log.Printf("Error fetching tokens: %v", err)
+			JSONError(w, "Failed to fetch tokens", http.StatusInternalServerError)
+			return nil, false
+		}
+		return tokens, true
+	}
+
+	var tokens []services.Token
+	seen := map[string]bool{}
+	for _, symbol := range symbols {
+		symbol = strings.TrimSpace(symbol)
+		if symbol == "" {
+			continue
+		}
+
+		token, err := h.tokenService.GetTokenBySymbol(r.Context(), symbol)
+		if err != nil {
+			JSONError(w, "Token not found or not supported: "+symbol, http.StatusBadRequest)
+			return nil, false
+		}
+		if seen[token.Symbol] {
+			continue
+		}
+		seen[token.Symbol] = true
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, true
+}
+
+// parseAllocations validates the symbols of portfolio allocations and rewrites them in their registered case.
+// Allocations without a symbol are left to the service to reject.
+func (h *Handler) parseAllocations(w http.ResponseWriter, r *http.Request, allocations []services.PortfolioAllocation) bool {
+	var symbols []string
+	for _, allocation := range allocations {
+		if strings.TrimSpace(allocation.Symbol) != "" {
+			symbols = append(symbols, allocation.Symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return true
+	}
+
+	tokens, ok := h.parseTokens(w, r, symbols)
+	if !ok {
+		return false
+	}
+
+	registered := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		registered[strings.ToLower(token.Symbol)] = token.Symbol
+	}
+	for i, allocation := range allocations {
+		if symbol, exists := registered[strings.ToLower(strings.TrimSpace(allocation.Symbol))]; exists {
+			allocations[i].Symbol = symbol
+		}
+	}
+
+	return true
+}
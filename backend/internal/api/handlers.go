@@ -1,168 +1,710 @@
-package api
-
-import (
-	"encoding/json"
-	"log"
-	"net/http"
-
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
-	"github.com/go-chi/chi/v5"
-)
-
-// Handler holds dependencies for HTTP handlers
-type Handler struct {
-	tokenService     *services.TokenService
-	valuationService *services.ValuationService
-}
-
-// NewHandler creates a new handler with dependencies
-func NewHandler(tokenService *services.TokenService, valuationService *services.ValuationService) *Handler {
-	return &Handler{
-		tokenService:     tokenService,
-		valuationService: valuationService,
-	}
-}
-
-// GetTokensHandler returns all active tokens
-//
-// @Summary Get all tracked LST tokens
-// @Description Retrieve a list of all active Liquid Staking Tokens being tracked
-// @Tags tokens
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]interface{} "tokens: array of token objects, count: number of tokens"
-// @Failure 500 {object} map[string]string "error: error message"
-// @Router /api/tokens [get]
-func (h *Handler) GetTokensHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	tokens, err := h.tokenService.GetAllTokens(r.Context())
-	if err != nil {
-		log.Printf("Error fetching tokens: %v", err)
-		JSONError(w, "Failed to fetch tokens", http.StatusInternalServerError)
-		return
-	}
-
-	JSONResponse(w, map[string]interface{}{
-		"tokens": tokens,
-		"count":  len(tokens),
-	})
-}
-
-// GetTokenHistoryHandler returns price history for a token
-//
-// @Summary Get price history for a token
-// @Description Retrieve 1-year price history for a specific LST token
-// @Tags tokens
-// @Accept json
-// @Produce json
-// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
-// @Success 200 {object} map[string]interface{} "price_history: array of price points, count: number of data points"
-// @Failure 400 {object} map[string]string "error: invalid token symbol"
-// @Failure 500 {object} map[string]string "error: failed to fetch price data"
-// @Router /api/token/{tokenSymbol}/history [get]
-func (h *Handler) GetTokenHistoryHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	tokenSymbol := chi.URLParam(r, "id") // Using 'id' param but it's actually the symbol
-
-	// Validate that the token exists
-	if err := h.tokenService.ValidateTokenExists(r.Context(), tokenSymbol); err != nil {
-		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
-		return
-	}
-
-	// Fetch price history
-	priceHistory, err := h.valuationService.GetTokenHistory(r.Context(), tokenSymbol)
-	if err != nil {
-		log.Printf("Error fetching price history for %s: %v", tokenSymbol, err)
-		JSONError(w, "Failed to fetch price history", http.StatusInternalServerError)
-		return
-	}
-
-	JSONResponse(w, map[string]interface{}{
-		"token_symbol": tokenSymbol,
-		"price_history": priceHistory,
-		"count": len(priceHistory),
-	})
-}
-
-// GetTokenValuationHandler returns valuation metrics for a specific token
-//
-// @Summary Get valuation metrics for a token
-// @Description Retrieve APR, stability, TVL, and valuation remarks for a specific LST token
-// @Tags tokens
-// @Accept json
-// @Produce json
-// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
-// @Success 200 {object} services.ValuationData "valuation metrics for the token"
-// @Failure 400 {object} map[string]string "error: invalid token symbol"
-// @Failure 500 {object} map[string]string "error: failed to calculate valuation"
-// @Router /api/token/{tokenSymbol}/valuation [get]
-func (h *Handler) GetTokenValuationHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	tokenSymbol := chi.URLParam(r, "id")
-
-	// Get token details
-	token, err := h.tokenService.GetTokenBySymbol(r.Context(), tokenSymbol)
-	if err != nil {
-		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
-		return
-	}
-
-	// Get valuation using service
-	valuation, err := h.valuationService.GetTokenValuation(r.Context(), tokenSymbol, token)
-	if err != nil {
-		log.Printf("Error getting valuation for %s: %v", tokenSymbol, err)
-		JSONError(w, "Failed to calculate valuation", http.StatusInternalServerError)
-		return
-	}
-
-	if err := json.NewEncoder(w).Encode(valuation); err != nil {
-		log.Printf("Error encoding response: %v", err)
-		JSONError(w, "Failed to encode response", http.StatusInternalServerError)
-		return
-	}
-}
-
-// GetAllValuationsHandler returns valuation metrics for all tokens
-//
-// @Summary Get valuation metrics for all tokens
-// @Description Retrieve APR, stability, TVL, and valuation remarks for all tracked LST tokens (sortable table data)
-// @Tags tokens
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]interface{} "valuations: array of valuation objects, count: number of valuations"
-// @Failure 500 {object} map[string]string "error: failed to fetch valuations"
-// @Router /api/valuations [get]
-func (h *Handler) GetAllValuationsHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	// Get all tokens
-	tokens, err := h.tokenService.GetAllTokens(r.Context())
-	if err != nil {
-		log.Printf("Error fetching tokens: %v", err)
-		JSONError(w, "Failed to fetch tokens", http.StatusInternalServerError)
-		return
-	}
-
-	// Get valuations for all tokens
-	valuations, err := h.valuationService.GetAllTokenValuations(r.Context(), tokens)
-	if err != nil {
-		log.Printf("Error getting valuations: %v", err)
-		JSONError(w, "Failed to fetch valuations", http.StatusInternalServerError)
-		return
-	}
-
-	JSONResponse(w, map[string]interface{}{
-		"valuations": valuations,
-		"count":      len(valuations),
-	})
-}
-
-func (h *Handler) RefreshCacheHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"message": "Cache refresh - TODO"}`))
-}
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/scheduler"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler holds dependencies for HTTP handlers
+type Handler struct {
+	tokenService     *services.TokenService
+	valuationService *services.ValuationService
+	scoringService   *services.ScoringService
+	scheduler        *scheduler.Scheduler
+}
+
+// NewHandler creates a new handler with dependencies
+func NewHandler(tokenService *services.TokenService, valuationService *services.ValuationService, scoringService *services.ScoringService, refreshScheduler *scheduler.Scheduler) *Handler {
+	return &Handler{
+		tokenService:     tokenService,
+		valuationService: valuationService,
+		scoringService:   scoringService,
+		scheduler:        refreshScheduler,
+	}
+}
+
+// GetTokensHandler returns all active tokens
+//
+// @Summary Get all tracked LST tokens
+// @Description Retrieve a list of all active Liquid Staking Tokens being tracked
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "tokens: array of token objects, count: number of tokens"
+// @Failure 500 {object} map[string]string "error: error message"
+// @Router /api/tokens [get]
+func (h *Handler) GetTokensHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokens, err := h.tokenService.GetAllTokens(r.Context())
+	if err != nil {
+		log.Printf("Error fetching tokens: %v", err)
+		JSONError(w, "Failed to fetch tokens", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"tokens": tokens,
+		"count":  len(tokens),
+	})
+}
+
+// GetTokenHistoryHandler returns price history for a token
+//
+// @Summary Get price history for a token
+// @Description Retrieve price history for a specific LST token over a range (default: last year, daily).
+// @Description Daily and weekly history is served from stored prices; hourly history covers at most 90 days.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param quote query string false "Quote currency of prices: eth (default), usd, eur or btc"
+// @Param from query string false "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)"
+// @Param to query string false "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)"
+// @Param days query int false "Number of days ending at to; cannot be combined with from"
+// @Param interval query string false "Granularity: hourly, daily (default) or weekly"
+// @Success 200 {object} map[string]interface{} "price_history: array of price points, count: number of data points"
+// @Failure 400 {object} map[string]string "error: invalid token symbol, quote currency, range or interval"
+// @Failure 500 {object} map[string]string "error: failed to fetch price data"
+// @Router /api/token/{tokenSymbol}/history [get]
+func (h *Handler) GetTokenHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id") // Using 'id' param but it's actually the symbol
+
+	// Validate that the token exists
+	if err := h.tokenService.ValidateTokenExists(r.Context(), tokenSymbol); err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Fetch price history
+	priceHistory, err := h.valuationService.GetTokenHistory(r.Context(), tokenSymbol, query)
+	if err != nil {
+		log.Printf("Error fetching price history for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to fetch price history", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"token_symbol":  tokenSymbol,
+		"quote":         query.Quote,
+		"interval":      query.Interval,
+		"from":          query.From,
+		"to":            query.To,
+		"price_history": priceHistory,
+		"count":         len(priceHistory),
+	})
+}
+
+// CompareTokensHandler returns aligned, rebased price series of several tokens
+//
+// @Summary Compare tokens
+// @Description Align the price histories of the selected tokens on shared timestamps of the interval, starting at the first one every token
+// @Description has a price, and rebase each series to 100 there. Intervals a token has no price for carry its previous price forward.
+// @Description Also returns the price of each token in every later listed token and the current valuation of each token.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param symbols query string true "Comma-separated token symbols, at least two (e.g., wstETH,rETH,CBETH)"
+// @Param quote query string false "Quote currency of prices: eth (default), usd, eur or btc"
+// @Param from query string false "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)"
+// @Param to query string false "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)"
+// @Param days query int false "Number of days ending at to; cannot be combined with from"
+// @Param interval query string false "Granularity: hourly, daily (default) or weekly"
+// @Param apr_method query string false "APR methodology of the valuations (default legacy)"
+// @Success 200 {object} services.TokenComparison "aligned series, price ratios and valuations"
+// @Failure 400 {object} map[string]string "error: invalid token symbols, quote currency, range, interval or APR method"
+// @Failure 500 {object} map[string]string "error: failed to compare tokens"
+// @Router /api/compare [get]
+func (h *Handler) CompareTokensHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.URL.Query().Get("symbols") == "" {
+		JSONError(w, "symbols is required", http.StatusBadRequest)
+		return
+	}
+	tokens, ok := h.parseTokensParam(w, r)
+	if !ok {
+		return
+	}
+	if len(tokens) < 2 {
+		JSONError(w, "At least two tokens are required", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	aprMethod, err := services.ParseAPRMethod(r.URL.Query().Get("apr_method"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comparison, err := h.valuationService.CompareTokens(r.Context(), tokens, query, aprMethod)
+	if err != nil {
+		if errors.Is(err, services.ErrInsufficientPriceData) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error comparing tokens: %v", err)
+		JSONError(w, "Failed to compare tokens", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, comparison)
+}
+
+// GetTokenCandlesHandler returns OHLC candles for a token
+//
+// @Summary Get OHLC candles for a token
+// @Description Aggregate the price history of a token into open/high/low/close buckets with point counts.
+// @Description Hourly and daily candles aggregate intraday prices and cover up to 90 days (default: 7 and 90 days); weekly candles aggregate daily prices (default: 1 year).
+// @Description Buckets without data have a zero count and null prices; buckets cut by the range or still in progress are marked partial.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param resolution query string false "Bucket size: 1h, 1d (default) or 1w"
+// @Param quote query string false "Quote currency of prices: eth (default), usd, eur or btc"
+// @Param from query string false "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)"
+// @Param to query string false "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)"
+// @Param days query int false "Number of days ending at to; cannot be combined with from"
+// @Success 200 {object} map[string]interface{} "candles: array of candles, count: number of candles"
+// @Failure 400 {object} map[string]string "error: invalid token symbol, resolution, quote currency or range"
+// @Failure 500 {object} map[string]string "error: failed to fetch price data"
+// @Router /api/token/{tokenSymbol}/candles [get]
+func (h *Handler) GetTokenCandlesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id")
+
+	// Validate that the token exists
+	if err := h.tokenService.ValidateTokenExists(r.Context(), tokenSymbol); err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parseCandleQuery(r)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	candles, err := h.valuationService.GetTokenCandles(r.Context(), tokenSymbol, query)
+	if err != nil {
+		log.Printf("Error fetching candles for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to fetch price history", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"token_symbol": tokenSymbol,
+		"quote":        query.Quote,
+		"resolution":   services.CandleResolution(query.Interval),
+		"from":         query.From,
+		"to":           query.To,
+		"candles":      candles,
+		"count":        len(candles),
+	})
+}
+
+// GetTokenRiskHandler returns risk metrics for a token
+//
+// @Summary Get risk metrics for a token
+// @Description Compute annualized volatility, max drawdown and its duration, downside deviation, Sharpe and Sortino ratios
+// @Description against a base staking rate, and historical 95% VaR/CVaR from the daily price history (default: last year).
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param quote query string false "Quote currency of prices: eth (default), usd, eur or btc"
+// @Param from query string false "Start of the range (RFC3339, YYYY-MM-DD or unix seconds)"
+// @Param to query string false "End of the range (RFC3339, YYYY-MM-DD or unix seconds, default: now)"
+// @Param days query int false "Number of days ending at to; cannot be combined with from"
+// @Param base_rate query number false "Annual base staking rate as a fraction (default: BASE_STAKING_RATE or 0.03)"
+// @Success 200 {object} services.RiskMetrics "risk metrics for the token"
+// @Failure 400 {object} map[string]string "error: invalid token symbol, quote currency, range or base rate, or insufficient price data"
+// @Failure 500 {object} map[string]string "error: failed to compute risk metrics"
+// @Router /api/token/{tokenSymbol}/risk [get]
+func (h *Handler) GetTokenRiskHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id")
+
+	// Validate that the token exists
+	if err := h.tokenService.ValidateTokenExists(r.Context(), tokenSymbol); err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parseHistoryRange(r, services.IntervalDaily, services.DefaultHistoryWindow(services.IntervalDaily))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	baseRate, err := parseFloatParam(r, "base_rate", services.BaseStakingRate())
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	risk, err := h.valuationService.GetTokenRisk(r.Context(), tokenSymbol, query, baseRate)
+	if errors.Is(err, services.ErrInsufficientPriceData) {
+		JSONError(w, "Not enough price data in the requested range", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error computing risk metrics for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to compute risk metrics", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, risk)
+}
+
+// GetTokenValuationHandler returns valuation metrics for a specific token
+//
+// @Summary Get valuation metrics for a token
+// @Description Retrieve APR, stability, TVL, and valuation remarks for a specific LST token
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param quote query string false "Quote currency of price and TVL: eth (default), usd, eur or btc"
+// @Param apr_method query string false "APR methodology: legacy (default), point_to_point, trailing_7d, trailing_30d, trailing_90d, log_regression or protocol_rate"
+// @Success 200 {object} services.ValuationData "valuation metrics for the token"
+// @Failure 400 {object} map[string]string "error: invalid token symbol, quote currency or APR method"
+// @Failure 500 {object} map[string]string "error: failed to calculate valuation"
+// @Failure 503 {object} map[string]string "error: valuation not computed yet"
+// @Router /api/token/{tokenSymbol}/valuation [get]
+func (h *Handler) GetTokenValuationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id")
+
+	// Get token details
+	token, err := h.tokenService.GetTokenBySymbol(r.Context(), tokenSymbol)
+	if err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := services.ParseQuote(r.URL.Query().Get("quote"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	aprMethod, err := services.ParseAPRMethod(r.URL.Query().Get("apr_method"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get valuation using service
+	valuation, err := h.valuationService.GetTokenValuation(r.Context(), tokenSymbol, token, quote, aprMethod)
+	if errors.Is(err, services.ErrNoExchangeRateAdapter) {
+		JSONError(w, "APR method protocol_rate requires a protocol exchange rate adapter for this token", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, services.ErrValuationNotReady) {
+		JSONError(w, "Valuation not computed yet, try again shortly", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting valuation for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to calculate valuation", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(valuation); err != nil {
+		log.Printf("Error encoding response: %v", err)
+		JSONError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetTokenValuationHistoryHandler returns stored valuation snapshots for a specific token
+//
+// @Summary Get valuation history for a token
+// @Description Retrieve timestamped valuation snapshots (price, APR, stability, TVL, remarks) for a specific LST token
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param from query string false "Start of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to 30 days before to"
+// @Param to query string false "End of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to now"
+// @Success 200 {object} map[string]interface{} "valuation_history: array of valuation snapshots, count: number of snapshots"
+// @Failure 400 {object} map[string]string "error: invalid token symbol or time range"
+// @Failure 500 {object} map[string]string "error: failed to fetch valuation history"
+// @Router /api/token/{tokenSymbol}/valuation/history [get]
+func (h *Handler) GetTokenValuationHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id")
+
+	// Validate that the token exists
+	if err := h.tokenService.ValidateTokenExists(r.Context(), tokenSymbol); err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseTimeRange(r, 30*24*time.Hour)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.valuationService.GetValuationHistory(r.Context(), tokenSymbol, from, to)
+	if err != nil {
+		log.Printf("Error fetching valuation history for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to fetch valuation history", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"token_symbol":      tokenSymbol,
+		"from":              from,
+		"to":                to,
+		"valuation_history": history,
+		"count":             len(history),
+	})
+}
+
+// GetTokenTVLHandler returns the TVL of a specific token with its per-chain breakdown
+//
+// @Summary Get TVL for a token
+// @Description Retrieve the token supply on its canonical chain and every registered bridged deployment. Canonical tokens locked in bridge escrows are excluded so bridged supply is not counted twice.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Success 200 {object} services.TVLData "total TVL and supply per chain"
+// @Failure 400 {object} map[string]string "error: invalid token symbol"
+// @Failure 500 {object} map[string]string "error: failed to fetch TVL"
+// @Router /api/token/{tokenSymbol}/tvl [get]
+func (h *Handler) GetTokenTVLHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id")
+
+	// Get token details
+	token, err := h.tokenService.GetTokenBySymbol(r.Context(), tokenSymbol)
+	if err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	tvl, err := h.valuationService.GetTokenTVL(r.Context(), token)
+	if err != nil {
+		log.Printf("Error fetching TVL for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to fetch TVL", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, tvl)
+}
+
+// GetTokenExchangeRateHandler returns the on-chain protocol exchange rate for a specific token
+//
+// @Summary Get protocol exchange rate for a token
+// @Description Read the token's redemption rate (underlying ETH per token) from its staking protocol contract, with the trailing 30-day protocol APR when the RPC node serves historical state
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Success 200 {object} services.ExchangeRateData "protocol exchange rate for the token"
+// @Failure 400 {object} map[string]string "error: invalid token symbol"
+// @Failure 404 {object} map[string]string "error: no exchange rate adapter for token"
+// @Failure 500 {object} map[string]string "error: failed to fetch exchange rate"
+// @Router /api/token/{tokenSymbol}/exchange-rate [get]
+func (h *Handler) GetTokenExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id")
+
+	// Get token details
+	token, err := h.tokenService.GetTokenBySymbol(r.Context(), tokenSymbol)
+	if err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	exchangeRate, err := h.valuationService.GetTokenExchangeRate(r.Context(), token)
+	if errors.Is(err, services.ErrNoExchangeRateAdapter) {
+		JSONError(w, "No protocol exchange rate available for this token", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching exchange rate for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to fetch exchange rate", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, exchangeRate)
+}
+
+// GetTokenPremiumHistoryHandler returns the market premium or discount to NAV over time for a specific token
+//
+// @Summary Get premium/discount to NAV history for a token
+// @Description Retrieve the market price, protocol exchange rate and premium in basis points (negative for a discount) from stored valuation snapshots
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param tokenSymbol path string true "Token symbol (e.g., wstETH, rETH)"
+// @Param from query string false "Start of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to 30 days before to"
+// @Param to query string false "End of the range (RFC3339, YYYY-MM-DD or unix seconds), defaults to now"
+// @Success 200 {object} map[string]interface{} "premium_history: array of premium points, count: number of points"
+// @Failure 400 {object} map[string]string "error: invalid token symbol or time range"
+// @Failure 404 {object} map[string]string "error: no exchange rate adapter for token"
+// @Failure 500 {object} map[string]string "error: failed to fetch premium history"
+// @Router /api/token/{tokenSymbol}/premium/history [get]
+func (h *Handler) GetTokenPremiumHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenSymbol := chi.URLParam(r, "id")
+
+	// Validate that the token exists
+	if err := h.tokenService.ValidateTokenExists(r.Context(), tokenSymbol); err != nil {
+		JSONError(w, "Token not found or not supported", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseTimeRange(r, 30*24*time.Hour)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.valuationService.GetPremiumHistory(r.Context(), tokenSymbol, from, to)
+	if errors.Is(err, services.ErrNoExchangeRateAdapter) {
+		JSONError(w, "No protocol exchange rate available for this token", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching premium history for %s: %v", tokenSymbol, err)
+		JSONError(w, "Failed to fetch premium history", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"token_symbol":    tokenSymbol,
+		"from":            from,
+		"to":              to,
+		"premium_history": history,
+		"count":           len(history),
+	})
+}
+
+// GetAllValuationsHandler returns valuation metrics for all tokens
+//
+// @Summary Get valuation metrics for all tokens
+// @Description Retrieve APR, stability, TVL, and valuation remarks for all tracked LST tokens (sortable table data)
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Param quote query string false "Quote currency of price and TVL: eth (default), usd, eur or btc"
+// @Param apr_method query string false "APR methodology: legacy (default), point_to_point, trailing_7d, trailing_30d, trailing_90d, log_regression or protocol_rate (tokens without a protocol APR fall back to legacy)"
+// @Param profile query string false "Scoring profile (e.g. balanced, conservative, yield-seeking); adds a composite score with its breakdown and sorts by it"
+// @Success 200 {object} map[string]interface{} "valuations: array of valuation objects, count: number of valuations"
+// @Failure 400 {object} map[string]string "error: invalid quote currency, APR method or scoring profile"
+// @Failure 500 {object} map[string]string "error: failed to fetch valuations"
+// @Router /api/valuations [get]
+func (h *Handler) GetAllValuationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	quote, err := services.ParseQuote(r.URL.Query().Get("quote"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	aprMethod, err := services.ParseAPRMethod(r.URL.Query().Get("apr_method"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var profile *services.ScoringProfile
+	if profileName := r.URL.Query().Get("profile"); profileName != "" {
+		profile, err = h.scoringService.GetProfile(r.Context(), profileName)
+		if errors.Is(err, services.ErrScoringProfileNotFound) {
+			JSONError(w, "Scoring profile not found: "+profileName, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Error fetching scoring profile %s: %v", profileName, err)
+			JSONError(w, "Failed to fetch scoring profile", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Get all tokens
+	tokens, err := h.tokenService.GetAllTokens(r.Context())
+	if err != nil {
+		log.Printf("Error fetching tokens: %v", err)
+		JSONError(w, "Failed to fetch tokens", http.StatusInternalServerError)
+		return
+	}
+
+	// Get valuations for all tokens
+	valuations, err := h.valuationService.GetAllTokenValuations(r.Context(), tokens, quote, aprMethod)
+	if err != nil {
+		log.Printf("Error getting valuations: %v", err)
+		JSONError(w, "Failed to fetch valuations", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"quote":      quote,
+		"apr_method": aprMethod,
+		"valuations": valuations,
+		"count":      len(valuations),
+	}
+	if profile != nil {
+		response["profile"] = profile.Name
+		response["valuations"] = h.scoringService.ScoreValuations(r.Context(), valuations, profile)
+	}
+
+	JSONResponse(w, response)
+}
+
+// GetScoringProfilesHandler returns the stored composite score weight profiles
+//
+// @Summary Get scoring profiles
+// @Description Retrieve the weight profiles that can be selected with ?profile= on /api/valuations
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "profiles: array of scoring profiles, count: number of profiles"
+// @Failure 500 {object} map[string]string "error: failed to fetch scoring profiles"
+// @Router /api/scoring-profiles [get]
+func (h *Handler) GetScoringProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	profiles, err := h.scoringService.GetProfiles(r.Context())
+	if err != nil {
+		log.Printf("Error fetching scoring profiles: %v", err)
+		JSONError(w, "Failed to fetch scoring profiles", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"profiles": profiles,
+		"count":    len(profiles),
+	})
+}
+
+// RefreshCacheRequest selects the tokens and data kinds to refresh
+type RefreshCacheRequest struct {
+	Symbols []string `json:"symbols" example:"wstETH,rETH"`
+	Kinds   []string `json:"kinds" example:"price_history,tvl,valuation"`
+}
+
+// RefreshCacheHandler starts an asynchronous cache refresh job
+//
+// @Summary Refresh cached data
+// @Description Invalidate and recompute cached data asynchronously. Omit symbols to refresh all tokens and omit kinds to refresh price_history, tvl and valuation. Only one refresh job runs at a time.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body RefreshCacheRequest false "Tokens and data kinds to refresh"
+// @Success 202 {object} scheduler.Job "refresh job with per-token progress"
+// @Failure 400 {object} map[string]string "error: invalid symbols or data kinds"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 409 {object} map[string]string "error: a refresh job is already running"
+// @Failure 500 {object} map[string]string "error: failed to start refresh"
+// @Router /api/cache/refresh [post]
+func (h *Handler) RefreshCacheHandler(w http.ResponseWriter, r *http.Request) {
+	var req RefreshCacheRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			JSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	kinds, err := scheduler.ParseDataKinds(req.Kinds)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var tokens []services.Token
+	if len(req.Symbols) == 0 {
+		tokens, err = h.tokenService.GetAllTokens(r.Context())
+		if err != nil {
+			log.Printf("Error fetching tokens: %v", err)
+			JSONError(w, "Failed to fetch tokens", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		for _, symbol := range req.Symbols {
+			token, err := h.tokenService.GetTokenBySymbol(r.Context(), symbol)
+			if err != nil {
+				JSONError(w, "Token not found or not supported: "+symbol, http.StatusBadRequest)
+				return
+			}
+			tokens = append(tokens, *token)
+		}
+	}
+
+	job, err := h.scheduler.StartJob(tokens, kinds)
+	if errors.Is(err, scheduler.ErrJobRunning) {
+		JSONError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error starting refresh job: %v", err)
+		JSONError(w, "Failed to start refresh", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/admin/cache/refresh/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GetRefreshJobHandler reports the progress of a cache refresh job
+//
+// @Summary Get cache refresh job status
+// @Description Retrieve the status of a cache refresh job, including per-token progress and errors
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param jobID path string true "Refresh job ID"
+// @Success 200 {object} scheduler.Job "refresh job with per-token progress"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: job not found"
+// @Router /api/cache/refresh/{jobID} [get]
+func (h *Handler) GetRefreshJobHandler(w http.ResponseWriter, r *http.Request) {
+	job := h.scheduler.GetJob(chi.URLParam(r, "jobID"))
+	if job == nil {
+		JSONError(w, "Refresh job not found", http.StatusNotFound)
+		return
+	}
+
+	JSONResponse(w, job)
+}
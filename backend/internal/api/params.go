@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+)
+
+// parseTimeParam parses a time query parameter given as RFC3339, YYYY-MM-DD or unix seconds.
+// The default value is returned when the parameter is absent.
+func parseTimeParam(r *http.Request, name string, defaultValue time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	return parseTimeValue(name, value)
+}
+
+// parseTimeValue parses a time given as RFC3339, YYYY-MM-DD or unix seconds
+func parseTimeValue(name, value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid %s: expected RFC3339, YYYY-MM-DD or unix seconds", name)
+}
+
+// parseDateRange parses the start_date and end_date of a request body, defaulting end_date to now.
+// start_date must be within the longest history range before now, so a request cannot make the
+// price stores backfill arbitrarily old history.
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	if startDate == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_date is required")
+	}
+	from, err := parseTimeValue("start_date", startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	to := time.Now().UTC()
+	if endDate != "" {
+		if to, err = parseTimeValue("end_date", endDate); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_date must not be after end_date")
+	}
+	if from.Before(services.EarliestHistoryStart()) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_date must be within the last %d days", services.MaxHistoryDays)
+	}
+
+	return from, to, nil
+}
+
+// parseFloatParam parses a numeric query parameter, returning the default value when the parameter is absent
+func parseFloatParam(r *http.Request, name string, defaultValue float64) (float64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return 0, fmt.Errorf("invalid %s: expected a number", name)
+	}
+
+	return parsed, nil
+}
+
+// parseTimeRange parses the from and to query parameters, defaulting to the given window ending now
+func parseTimeRange(r *http.Request, defaultWindow time.Duration) (time.Time, time.Time, error) {
+	to, err := parseTimeParam(r, "to", time.Now().UTC())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from, err := parseTimeParam(r, "from", to.Add(-defaultWindow))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+
+	return from, to, nil
+}
+
+// parseHistoryQuery parses the from, to, days, interval and quote query parameters of a history request
+func parseHistoryQuery(r *http.Request) (services.HistoryQuery, error) {
+	interval, err := services.ParseInterval(r.URL.Query().Get("interval"))
+	if err != nil {
+		return services.HistoryQuery{}, err
+	}
+
+	return parseHistoryRange(r, interval, services.DefaultHistoryWindow(interval))
+}
+
+// parseCandleQuery parses the from, to, days, resolution and quote query parameters of a candles request
+func parseCandleQuery(r *http.Request) (services.HistoryQuery, error) {
+	interval, err := services.ParseResolution(r.URL.Query().Get("resolution"))
+	if err != nil {
+		return services.HistoryQuery{}, err
+	}
+
+	query, err := parseHistoryRange(r, interval, services.DefaultCandleWindow(interval))
+	if err != nil {
+		return services.HistoryQuery{}, err
+	}
+	if err := services.ValidateCandleQuery(query); err != nil {
+		return services.HistoryQuery{}, err
+	}
+
+	return query, nil
+}
+
+// parseHistoryRange parses the from, to, days and quote query parameters of a history query at an interval.
+// days selects the range ending at to and cannot be combined with from; without either the range
+// defaults to the given window.
+func parseHistoryRange(r *http.Request, interval string, defaultWindow time.Duration) (services.HistoryQuery, error) {
+	params := r.URL.Query()
+
+	quote, err := services.ParseQuote(params.Get("quote"))
+	if err != nil {
+		return services.HistoryQuery{}, err
+	}
+
+	to, err := parseTimeParam(r, "to", time.Now().UTC())
+	if err != nil {
+		return services.HistoryQuery{}, err
+	}
+
+	window := defaultWindow
+	if daysStr := params.Get("days"); daysStr != "" {
+		if params.Get("from") != "" {
+			return services.HistoryQuery{}, fmt.Errorf("days and from cannot be combined")
+		}
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 || days > services.MaxHistoryDays {
+			return services.HistoryQuery{}, fmt.Errorf("invalid days: expected an integer between 1 and %d", services.MaxHistoryDays)
+		}
+		window = time.Duration(days) * 24 * time.Hour
+	}
+
+	from, err := parseTimeParam(r, "from", to.Add(-window))
+	if err != nil {
+		return services.HistoryQuery{}, err
+	}
+
+	query := services.HistoryQuery{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Quote:    quote,
+	}
+	if err := query.Validate(); err != nil {
+		return services.HistoryQuery{}, err
+	}
+
+	return query, nil
+}
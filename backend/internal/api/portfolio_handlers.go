@@ -0,0 +1,315 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+)
+
+// PortfolioSimulationRequest describes a portfolio to replay over stored price history
+type PortfolioSimulationRequest struct {
+	Allocations        []services.PortfolioAllocation `json:"allocations"`
+	StartDate          string                         `json:"start_date" example:"2024-01-01"`
+	EndDate            string                         `json:"end_date,omitempty" example:"2024-12-31"`
+	Rebalance          string                         `json:"rebalance,omitempty" example:"monthly"`
+	RebalanceThreshold float64                        `json:"rebalance_threshold,omitempty" example:"0.05"`
+	InitialValue       float64                        `json:"initial_value,omitempty" example:"1"`
+}
+
+// SimulatePortfolioHandler replays a portfolio over stored daily prices
+//
+// @Summary Simulate a portfolio
+// @Description Replay an allocation over the stored daily ETH prices of its tokens and return the daily portfolio value,
+// @Description blended APR, volatility and drawdown. Allocations are given either all as weights (normalized, scaled to initial_value ETH)
+// @Description or all as ETH amounts. The simulation starts on the first day every token has a price at or after start_date.
+// @Description Rebalance policies: none (default), daily, weekly, monthly, quarterly, or threshold (whenever a weight drifts more than rebalance_threshold, default 0.05, from its target).
+// @Tags portfolio
+// @Accept json
+// @Produce json
+// @Param request body PortfolioSimulationRequest true "Allocation, date range and rebalancing policy"
+// @Success 200 {object} services.PortfolioSimulation "portfolio value series and metrics, values in ETH"
+// @Failure 400 {object} map[string]string "error: invalid allocation, dates, rebalance policy or insufficient price data"
+// @Failure 500 {object} map[string]string "error: failed to simulate portfolio"
+// @Router /api/portfolio/simulate [post]
+func (h *Handler) SimulatePortfolioHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req PortfolioSimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Rebalance == "" {
+		req.Rebalance = services.RebalanceNone
+	}
+
+	if !h.parseAllocations(w, r, req.Allocations) {
+		return
+	}
+
+	simulation, err := h.valuationService.SimulatePortfolio(r.Context(), services.PortfolioSimulationInput{
+		Allocations:        req.Allocations,
+		From:               from,
+		To:                 to,
+		Rebalance:          req.Rebalance,
+		RebalanceThreshold: req.RebalanceThreshold,
+		InitialValue:       req.InitialValue,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPortfolio) || errors.Is(err, services.ErrInsufficientPriceData) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error simulating portfolio: %v", err)
+		JSONError(w, "Failed to simulate portfolio", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, simulation)
+}
+
+// PortfolioOptimizationRequest selects the tokens, objective and constraints of an allocation optimization
+type PortfolioOptimizationRequest struct {
+	Symbols          []string `json:"symbols,omitempty" example:"wstETH,rETH,cbETH"`
+	Objective        string   `json:"objective,omitempty" example:"mean_variance"`
+	RiskAversion     *float64 `json:"risk_aversion,omitempty" example:"10"`
+	MaxWeight        float64  `json:"max_weight,omitempty" example:"0.5"`
+	MinTVL           float64  `json:"min_tvl,omitempty" example:"10000"`
+	ExcludeCustodial bool     `json:"exclude_custodial,omitempty"`
+	Window           string   `json:"window,omitempty" example:"365d"`
+	FrontierPoints   int      `json:"frontier_points,omitempty" example:"20"`
+}
+
+// OptimizePortfolioHandler solves for recommended token weights and the efficient frontier
+//
+// @Summary Optimize a portfolio allocation
+// @Description Estimate annualized expected returns and covariances from the daily ETH returns of the selected tokens (default: all) over the window
+// @Description (default 365d), then solve long-only allocations capped at max_weight. Custodial tokens are dropped with exclude_custodial and tokens
+// @Description below min_tvl ETH of TVL are dropped. min_variance recommends the lowest-volatility allocation; mean_variance (default) maximizes
+// @Description expected return minus risk_aversion/2 times variance, or picks the highest Sharpe ratio on the frontier when risk_aversion is omitted.
+// @Tags portfolio
+// @Accept json
+// @Produce json
+// @Param request body PortfolioOptimizationRequest false "Objective and constraints"
+// @Success 200 {object} services.PortfolioOptimization "recommended weights and efficient frontier"
+// @Failure 400 {object} map[string]string "error: invalid objective, constraints, window or token symbol"
+// @Failure 500 {object} map[string]string "error: failed to optimize portfolio"
+// @Router /api/portfolio/optimize [post]
+func (h *Handler) OptimizePortfolioHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req PortfolioOptimizationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			JSONError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	window := 365 * 24 * time.Hour
+	if req.Window != "" {
+		parsed, err := services.ParseWindow(req.Window)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+	if req.Objective == "" {
+		req.Objective = services.ObjectiveMeanVariance
+	}
+
+	tokens, ok := h.parseTokens(w, r, req.Symbols)
+	if !ok {
+		return
+	}
+
+	to := time.Now().UTC()
+	optimization, err := h.valuationService.OptimizePortfolio(r.Context(), tokens, services.PortfolioOptimizationInput{
+		Objective:        req.Objective,
+		RiskAversion:     req.RiskAversion,
+		MaxWeight:        req.MaxWeight,
+		MinTVL:           req.MinTVL,
+		ExcludeCustodial: req.ExcludeCustodial,
+		From:             to.Add(-window),
+		To:               to,
+		FrontierPoints:   req.FrontierPoints,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOptimization) || errors.Is(err, services.ErrInsufficientPriceData) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error optimizing portfolio: %v", err)
+		JSONError(w, "Failed to optimize portfolio", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, optimization)
+}
+
+// BacktestRequest is a strategy and the range to run it over
+type BacktestRequest struct {
+	services.Strategy
+	StartDate    string  `json:"start_date" example:"2024-01-01"`
+	EndDate      string  `json:"end_date,omitempty" example:"2024-12-31"`
+	InitialValue float64 `json:"initial_value,omitempty" example:"1"`
+}
+
+// BacktestHandler runs a rule-based strategy over stored history
+//
+// @Summary Backtest a strategy
+// @Description Run declarative rotation rules over stored daily ETH prices, replayed APR, stability and remarks, and the TVL and premium of stored
+// @Description valuation snapshots. At each rebalance (daily, weekly, monthly by default, or quarterly) held tokens meeting all exit conditions are sold
+// @Description and free slots up to max_positions are filled with the tokens meeting all entry conditions, best ranked by rank_by first; without exit
+// @Description conditions holdings rotate into the top candidates. Trades execute at the daily close and pay swap_cost_bps on the traded value.
+// @Description Condition metrics: apr, stability, tvl, premium_bps (ops >, >=, <, <=, ==, != with value) and remarks (== or != with value_string, in or not_in with values).
+// @Tags portfolio
+// @Accept json
+// @Produce json
+// @Param request body BacktestRequest true "Strategy rules, universe and date range"
+// @Success 200 {object} services.StrategyBacktest "equity curve, trades and summary statistics, values in ETH"
+// @Failure 400 {object} map[string]string "error: invalid strategy, dates, token symbol or insufficient price data"
+// @Failure 500 {object} map[string]string "error: failed to run backtest"
+// @Router /api/backtest [post]
+func (h *Handler) BacktestHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req BacktestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Default to every active token and use the registered case of given symbols
+	tokens, ok := h.parseTokens(w, r, req.Strategy.Symbols)
+	if !ok {
+		return
+	}
+	strategy := req.Strategy
+	strategy.Symbols = make([]string, len(tokens))
+	for i, token := range tokens {
+		strategy.Symbols[i] = token.Symbol
+	}
+
+	backtest, err := h.valuationService.BacktestStrategy(r.Context(), services.StrategyBacktestInput{
+		Strategy:     strategy,
+		From:         from,
+		To:           to,
+		InitialValue: req.InitialValue,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStrategy) || errors.Is(err, services.ErrUnsupportedAPRMethod) ||
+			errors.Is(err, services.ErrInsufficientPriceData) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error running backtest: %v", err)
+		JSONError(w, "Failed to run backtest", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, backtest)
+}
+
+// ProjectionRequest is a token or allocation to project over a horizon
+type ProjectionRequest struct {
+	Symbol      string                         `json:"symbol,omitempty" example:"wstETH"`
+	Allocations []services.PortfolioAllocation `json:"allocations,omitempty"`
+	AmountETH   float64                        `json:"amount_eth,omitempty" example:"10"`
+	HorizonDays int                            `json:"horizon_days" example:"365"`
+	Method      string                         `json:"method,omitempty" example:"bootstrap"`
+	Simulations int                            `json:"simulations,omitempty" example:"5000"`
+	Window      string                         `json:"window,omitempty" example:"365d"`
+	Seed        *int64                         `json:"seed,omitempty"`
+}
+
+// ProjectYieldHandler projects the range of staking rewards of a holding
+//
+// @Summary Project staking rewards with uncertainty bands
+// @Description Simulate the value of amount_eth held in a token (symbol) or an allocation (weights, or ETH amounts which set the amount) over
+// @Description horizon_days by resampling the weighted daily ETH returns of the window (default 365d): bootstrap (default) draws historical days
+// @Description with replacement, monte_carlo draws normal returns with the historical mean and volatility. Returns the median and 5th-95th
+// @Description percentile final value, rewards and APR, the probability of loss, and value percentile bands over the horizon. Pass seed to reproduce a run.
+// @Tags portfolio
+// @Accept json
+// @Produce json
+// @Param request body ProjectionRequest true "Holding, amount, horizon and resampling method"
+// @Success 200 {object} services.YieldProjection "percentiles of projected value, rewards and APR"
+// @Failure 400 {object} map[string]string "error: invalid holding, amount, horizon, method or insufficient price data"
+// @Failure 500 {object} map[string]string "error: failed to project yield"
+// @Router /api/projection [post]
+func (h *Handler) ProjectYieldHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req ProjectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if (req.Symbol == "") == (len(req.Allocations) == 0) {
+		JSONError(w, "Exactly one of symbol and allocations is required", http.StatusBadRequest)
+		return
+	}
+	if req.Symbol != "" {
+		weight := 1.0
+		req.Allocations = []services.PortfolioAllocation{{Symbol: req.Symbol, Weight: &weight}}
+	}
+
+	window := 365 * 24 * time.Hour
+	if req.Window != "" {
+		parsed, err := services.ParseWindow(req.Window)
+		if err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		window = parsed
+	}
+
+	if !h.parseAllocations(w, r, req.Allocations) {
+		return
+	}
+
+	to := time.Now().UTC()
+	projection, err := h.valuationService.ProjectYield(r.Context(), services.ProjectionInput{
+		Allocations: req.Allocations,
+		AmountETH:   req.AmountETH,
+		HorizonDays: req.HorizonDays,
+		Method:      req.Method,
+		Simulations: req.Simulations,
+		From:        to.Add(-window),
+		To:          to,
+		Seed:        req.Seed,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProjection) || errors.Is(err, services.ErrInvalidPortfolio) ||
+			errors.Is(err, services.ErrInsufficientPriceData) {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error projecting yield: %v", err)
+		JSONError(w, "Failed to project yield", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, projection)
+}
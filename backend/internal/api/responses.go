@@ -1,20 +1,20 @@
-package api
-
-import (
-	"encoding/json"
-	"net/http"
-)
-
-// JSONResponse sends a JSON response with the given data
-func JSONResponse(w http.ResponseWriter, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(data)
-}
-
-// JSONError sends a JSON error response with the given message and status code
-func JSONError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
-}
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JSONResponse sends a JSON response with the given data
+func JSONResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(data)
+}
+
+// JSONError sends a JSON error response with the given message and status code
+func JSONError(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
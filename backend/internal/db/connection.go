@@ -1,42 +1,61 @@
-package db
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-	"os"
-
-	_ "github.com/lib/pq"
-)
-
-var DB *sql.DB
-
-// InitDB initializes the PostgreSQL database connection
-func InitDB() error {
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL == "" {
-		return fmt.Errorf("DATABASE_URL environment variable is required")
-	}
-
-	var err error
-	DB, err = sql.Open("postgres", databaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database connection: %w", err)
-	}
-
-	// Test the connection
-	if err := DB.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	log.Println("Database connection established")
-	return nil
-}
-
-// CloseDB closes the database connection
-func CloseDB() error {
-	if DB != nil {
-		return DB.Close()
-	}
-	return nil
-}
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+var DB *sql.DB
+
+// InitDB initializes the PostgreSQL database connection and applies pending migrations
+func InitDB() error {
+	if err := Connect(); err != nil {
+		return err
+	}
+
+	// Migrations can be disabled to run them separately via the migrate subcommand
+	if os.Getenv("DB_AUTO_MIGRATE") == "false" {
+		log.Println("Automatic database migrations disabled")
+		return nil
+	}
+
+	if err := MigrateUp(); err != nil {
+		return fmt.Errorf("failed to apply database migrations: %w", err)
+	}
+
+	return nil
+}
+
+// Connect opens the PostgreSQL database connection without applying migrations
+func Connect() error {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("DATABASE_URL environment variable is required")
+	}
+
+	var err error
+	DB, err = sql.Open("postgres", databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// Test the connection
+	if err := DB.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	log.Println("Database connection established")
+	return nil
+}
+
+// CloseDB closes the database connection
+func CloseDB() error {
+	if DB != nil {
+		return DB.Close()
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+-- Persisted price history, one row per token and timestamp
+CREATE TABLE IF NOT EXISTS price_points (
+    token_id INTEGER NOT NULL REFERENCES tokens(id) ON DELETE CASCADE,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (token_id, recorded_at)
+);
@@ -0,0 +1,14 @@
+-- Every computed valuation, stored as a timestamped snapshot
+CREATE TABLE IF NOT EXISTS valuation_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    token_id INTEGER NOT NULL REFERENCES tokens(id) ON DELETE CASCADE,
+    price DOUBLE PRECISION NOT NULL,
+    apr DOUBLE PRECISION NOT NULL,
+    stability DOUBLE PRECISION NOT NULL,
+    tvl DOUBLE PRECISION NOT NULL,
+    remarks VARCHAR(32) NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_valuation_snapshots_token_computed_at
+    ON valuation_snapshots (token_id, computed_at);
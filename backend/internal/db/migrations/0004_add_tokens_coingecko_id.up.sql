@@ -0,0 +1,20 @@
+-- CoinGecko coin ID per token, resolved from the contract address when missing
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS coingecko_id VARCHAR(100);
+
+UPDATE tokens SET coingecko_id = v.coingecko_id
+FROM (VALUES
+    ('wstETH', 'wrapped-steth'),
+    ('ankrETH', 'ankreth'),
+    ('rETH', 'rocket-pool-eth'),
+    ('wBETH', 'wrapped-beacon-eth'),
+    ('pufETH', 'pufeth'),
+    ('LSETH', 'liquid-staked-ethereum'),
+    ('RSETH', 'kelp-dao-restaked-eth'),
+    ('METH', 'mantle-staked-ether'),
+    ('CBETH', 'coinbase-wrapped-staked-eth'),
+    ('TETH', 'treehouse-eth'),
+    ('SFRXETH', 'staked-frax-ether'),
+    ('CDCETH', 'crypto-com-staked-eth'),
+    ('UNIETH', 'universal-eth')
+) AS v(symbol, coingecko_id)
+WHERE tokens.symbol = v.symbol AND tokens.coingecko_id IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE price_points DROP COLUMN IF EXISTS source;
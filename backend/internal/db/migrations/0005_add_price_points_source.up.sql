@@ -0,0 +1,2 @@
+-- Provider that produced each stored price point
+ALTER TABLE price_points ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'coingecko';
@@ -0,0 +1,2 @@
+ALTER TABLE valuation_snapshots DROP COLUMN IF EXISTS protocol_apr;
+ALTER TABLE valuation_snapshots DROP COLUMN IF EXISTS exchange_rate;
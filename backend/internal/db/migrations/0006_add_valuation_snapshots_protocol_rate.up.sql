@@ -0,0 +1,3 @@
+-- On-chain redemption rate and the APR derived from it; NULL for tokens without a protocol adapter
+ALTER TABLE valuation_snapshots ADD COLUMN IF NOT EXISTS exchange_rate DOUBLE PRECISION;
+ALTER TABLE valuation_snapshots ADD COLUMN IF NOT EXISTS protocol_apr DOUBLE PRECISION;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS token_deployments;
+
+ALTER TABLE tokens DROP CONSTRAINT IF EXISTS tokens_blockchain_contract_address_key;
+ALTER TABLE tokens ADD CONSTRAINT tokens_contract_address_key UNIQUE (contract_address);
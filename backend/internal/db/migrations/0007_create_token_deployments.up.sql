@@ -0,0 +1,18 @@
+-- Contract addresses are unique per chain; the same address may be deployed on several chains
+ALTER TABLE tokens DROP CONSTRAINT IF EXISTS tokens_contract_address_key;
+ALTER TABLE tokens ADD CONSTRAINT tokens_blockchain_contract_address_key UNIQUE (blockchain, contract_address);
+
+-- Bridged deployments of a token on chains other than its canonical chain (tokens.blockchain).
+-- escrow_address is the bridge contract on the canonical chain that locks the canonical tokens
+-- backing this deployment; its balance is excluded from canonical supply to avoid double counting.
+CREATE TABLE IF NOT EXISTS token_deployments (
+    id SERIAL PRIMARY KEY,
+    token_id INTEGER NOT NULL REFERENCES tokens(id) ON DELETE CASCADE,
+    blockchain VARCHAR(20) NOT NULL,
+    contract_address VARCHAR(42) NOT NULL,
+    decimals INTEGER NOT NULL DEFAULT 18,
+    escrow_address VARCHAR(42),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (token_id, blockchain),
+    UNIQUE (blockchain, contract_address)
+);
@@ -0,0 +1,4 @@
+UPDATE valuation_snapshots SET tvl = supply;
+
+ALTER TABLE valuation_snapshots DROP COLUMN IF EXISTS tvl_usd;
+ALTER TABLE valuation_snapshots DROP COLUMN IF EXISTS supply;
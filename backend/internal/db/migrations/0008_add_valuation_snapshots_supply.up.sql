@@ -0,0 +1,6 @@
+-- tvl used to hold the raw token supply; keep it as supply and value the existing rows in ETH
+-- with the market price recorded in the same snapshot
+ALTER TABLE valuation_snapshots ADD COLUMN IF NOT EXISTS supply DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE valuation_snapshots ADD COLUMN IF NOT EXISTS tvl_usd DOUBLE PRECISION;
+
+UPDATE valuation_snapshots SET supply = tvl, tvl = tvl * price;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS eth_quote_points;
@@ -0,0 +1,7 @@
+-- Daily ETH price in each quote currency, used to convert ETH-denominated prices
+CREATE TABLE IF NOT EXISTS eth_quote_points (
+    currency VARCHAR(8) NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (currency, recorded_at)
+);
@@ -0,0 +1 @@
+ALTER TABLE valuation_snapshots DROP COLUMN IF EXISTS apr_method;
@@ -0,0 +1,2 @@
+-- Methodology the stored APR was computed with; existing rows used the legacy monthly-average method
+ALTER TABLE valuation_snapshots ADD COLUMN IF NOT EXISTS apr_method VARCHAR(32) NOT NULL DEFAULT 'legacy';
@@ -0,0 +1,17 @@
+-- Weight profiles for the composite token score, keyed by component name
+CREATE TABLE IF NOT EXISTS scoring_profiles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    weights JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO scoring_profiles (name, description, weights) VALUES
+    ('balanced', 'Even mix of yield, stability, size and peg quality',
+     '{"apr": 0.25, "stability": 0.2, "tvl": 0.15, "valuation": 0.1, "depeg": 0.15, "liquidity": 0.15}'),
+    ('conservative', 'Favors stable, large, liquid tokens that hold their peg',
+     '{"apr": 0.1, "stability": 0.3, "tvl": 0.2, "valuation": 0.05, "depeg": 0.2, "liquidity": 0.15}'),
+    ('yield-seeking', 'Favors yield and undervaluation',
+     '{"apr": 0.45, "stability": 0.1, "tvl": 0.1, "valuation": 0.15, "depeg": 0.1, "liquidity": 0.1}')
+ON CONFLICT (name) DO NOTHING;
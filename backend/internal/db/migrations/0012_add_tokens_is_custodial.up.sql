@@ -0,0 +1,5 @@
+-- Tokens whose staking is run by a centralized custodian rather than a protocol
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS is_custodial BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE tokens SET is_custodial = true
+WHERE symbol IN ('CBETH', 'wBETH', 'CDCETH');
@@ -0,0 +1,2 @@
+-- 24h trading volume in USD at the time of the valuation; NULL when CoinGecko did not report it
+ALTER TABLE valuation_snapshots ADD COLUMN IF NOT EXISTS volume_24h DOUBLE PRECISION;
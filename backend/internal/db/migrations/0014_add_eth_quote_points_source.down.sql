@@ -0,0 +1 @@
+ALTER TABLE eth_quote_points DROP COLUMN IF EXISTS source;
@@ -0,0 +1,2 @@
+-- Provider that produced each stored ETH price, so quote series share the price_points layout
+ALTER TABLE eth_quote_points ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'coingecko';
@@ -1,112 +1,261 @@
-package db
-
-import (
-	"time"
-)
-
-// Token represents a token in the database
-type Token struct {
-	ID             int       `json:"id"`
-	Symbol         string    `json:"symbol"`
-	Name           string    `json:"name"`
-	ContractAddress string   `json:"contract_address"`
-	Decimals       int       `json:"decimals"`
-	Blockchain     string    `json:"blockchain"`
-	IsActive       bool      `json:"is_active"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-}
-
-// GetAllTokens retrieves all active tokens from the database
-func GetAllTokens() ([]Token, error) {
-	query := `
-		SELECT id, symbol, name, contract_address, decimals, blockchain, is_active, created_at, updated_at
-		FROM tokens
-		WHERE is_active = true
-		ORDER BY symbol
-	`
-
-	rows, err := DB.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var tokens []Token
-	for rows.Next() {
-		var token Token
-		err := rows.Scan(
-			&token.ID,
-			&token.Symbol,
-			&token.Name,
-			&token.ContractAddress,
-			&token.Decimals,
-			&token.Blockchain,
-			&token.IsActive,
-			&token.CreatedAt,
-			&token.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		tokens = append(tokens, token)
-	}
-
-	return tokens, rows.Err()
-}
-
-// GetTokenByID retrieves a token by its ID
-func GetTokenByID(id int) (*Token, error) {
-	query := `
-		SELECT id, symbol, name, contract_address, decimals, blockchain, is_active, created_at, updated_at
-		FROM tokens
-		WHERE id = $1 AND is_active = true
-	`
-
-	var token Token
-	err := DB.QueryRow(query, id).Scan(
-		&token.ID,
-		&token.Symbol,
-		&token.Name,
-		&token.ContractAddress,
-		&token.Decimals,
-		&token.Blockchain,
-		&token.IsActive,
-		&token.CreatedAt,
-		&token.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &token, nil
-}
-
-// GetTokenBySymbol retrieves a token by its symbol
-func GetTokenBySymbol(symbol string) (*Token, error) {
-	query := `
-		SELECT id, symbol, name, contract_address, decimals, blockchain, is_active, created_at, updated_at
-		FROM tokens
-		WHERE symbol = $1 AND is_active = true
-	`
-
-	var token Token
-	err := DB.QueryRow(query, symbol).Scan(
-		&token.ID,
-		&token.Symbol,
-		&token.Name,
-		&token.ContractAddress,
-		&token.Decimals,
-		&token.Blockchain,
-		&token.IsActive,
-		&token.CreatedAt,
-		&token.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &token, nil
-}
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrTokenNotFound is returned when no token matches the requested symbol
+var ErrTokenNotFound = errors.New("token not found")
+
+// ErrTokenExists is returned when a token with the same symbol or contract address already exists
+var ErrTokenExists = errors.New("token already exists")
+
+// Token represents a token in the database
+type Token struct {
+	ID              int       `json:"id"`
+	Symbol          string    `json:"symbol"`
+	Name            string    `json:"name"`
+	ContractAddress string    `json:"contract_address"`
+	Decimals        int       `json:"decimals"`
+	Blockchain      string    `json:"blockchain"`
+	CoinGeckoID     string    `json:"coingecko_id"`
+	IsActive        bool      `json:"is_active"`
+	IsCustodial     bool      `json:"is_custodial"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// GetAllTokens retrieves all active tokens from the database
+func GetAllTokens() ([]Token, error) {
+	query := `
+		SELECT id, symbol, name, contract_address, decimals, blockchain, COALESCE(coingecko_id, ''), is_active, is_custodial, created_at, updated_at
+		FROM tokens
+		WHERE is_active = true
+		ORDER BY symbol
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []Token
+	for rows.Next() {
+		var token Token
+		err := rows.Scan(
+			&token.ID,
+			&token.Symbol,
+			&token.Name,
+			&token.ContractAddress,
+			&token.Decimals,
+			&token.Blockchain,
+			&token.CoinGeckoID,
+			&token.IsActive,
+			&token.IsCustodial,
+			&token.CreatedAt,
+			&token.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// GetTokenByID retrieves a token by its ID
+func GetTokenByID(id int) (*Token, error) {
+	query := `
+		SELECT id, symbol, name, contract_address, decimals, blockchain, COALESCE(coingecko_id, ''), is_active, is_custodial, created_at, updated_at
+		FROM tokens
+		WHERE id = $1 AND is_active = true
+	`
+
+	var token Token
+	err := DB.QueryRow(query, id).Scan(
+		&token.ID,
+		&token.Symbol,
+		&token.Name,
+		&token.ContractAddress,
+		&token.Decimals,
+		&token.Blockchain,
+		&token.CoinGeckoID,
+		&token.IsActive,
+		&token.IsCustodial,
+		&token.CreatedAt,
+		&token.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetTokenBySymbol retrieves a token by its symbol
+func GetTokenBySymbol(symbol string) (*Token, error) {
+	query := `
+		SELECT id, symbol, name, contract_address, decimals, blockchain, COALESCE(coingecko_id, ''), is_active, is_custodial, created_at, updated_at
+		FROM tokens
+		WHERE symbol = $1 AND is_active = true
+	`
+
+	var token Token
+	err := DB.QueryRow(query, symbol).Scan(
+		&token.ID,
+		&token.Symbol,
+		&token.Name,
+		&token.ContractAddress,
+		&token.Decimals,
+		&token.Blockchain,
+		&token.CoinGeckoID,
+		&token.IsActive,
+		&token.IsCustodial,
+		&token.CreatedAt,
+		&token.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// CreateToken inserts a new token and returns it with generated fields populated
+func CreateToken(token Token) (*Token, error) {
+	query := `
+		INSERT INTO tokens (symbol, name, contract_address, decimals, blockchain, coingecko_id, is_active, is_custodial)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING id, symbol, name, contract_address, decimals, blockchain, COALESCE(coingecko_id, ''), is_active, is_custodial, created_at, updated_at
+	`
+
+	var created Token
+	err := DB.QueryRow(query,
+		token.Symbol,
+		token.Name,
+		token.ContractAddress,
+		token.Decimals,
+		token.Blockchain,
+		token.CoinGeckoID,
+		token.IsActive,
+		token.IsCustodial,
+	).Scan(
+		&created.ID,
+		&created.Symbol,
+		&created.Name,
+		&created.ContractAddress,
+		&created.Decimals,
+		&created.Blockchain,
+		&created.CoinGeckoID,
+		&created.IsActive,
+		&created.IsCustodial,
+		&created.CreatedAt,
+		&created.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, translateTokenError(err)
+	}
+
+	return &created, nil
+}
+
+// UpdateToken updates the token with the given symbol, including inactive tokens.
+// The active and custodial flags are set from isActive and isCustodial rather than token, and are kept when nil.
+func UpdateToken(symbol string, token Token, isActive, isCustodial *bool) (*Token, error) {
+	query := `
+		UPDATE tokens
+		SET name = $2, contract_address = $3, decimals = $4, blockchain = $5, coingecko_id = NULLIF($6, ''), is_active = COALESCE($7, is_active),
+			is_custodial = COALESCE($8, is_custodial), updated_at = CURRENT_TIMESTAMP
+		WHERE symbol = $1
+		RETURNING id, symbol, name, contract_address, decimals, blockchain, COALESCE(coingecko_id, ''), is_active, is_custodial, created_at, updated_at
+	`
+
+	var updated Token
+	err := DB.QueryRow(query,
+		symbol,
+		token.Name,
+		token.ContractAddress,
+		token.Decimals,
+		token.Blockchain,
+		token.CoinGeckoID,
+		isActive,
+		isCustodial,
+	).Scan(
+		&updated.ID,
+		&updated.Symbol,
+		&updated.Name,
+		&updated.ContractAddress,
+		&updated.Decimals,
+		&updated.Blockchain,
+		&updated.CoinGeckoID,
+		&updated.IsActive,
+		&updated.IsCustodial,
+		&updated.CreatedAt,
+		&updated.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, translateTokenError(err)
+	}
+
+	return &updated, nil
+}
+
+// SetTokenCoinGeckoID stores the resolved CoinGecko coin ID for the token with the given symbol
+func SetTokenCoinGeckoID(symbol, coinGeckoID string) error {
+	query := `
+		UPDATE tokens
+		SET coingecko_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE symbol = $1
+	`
+
+	_, err := DB.Exec(query, symbol, coinGeckoID)
+	return err
+}
+
+// DeactivateToken marks the token with the given symbol as inactive, keeping its stored history
+func DeactivateToken(symbol string) error {
+	query := `
+		UPDATE tokens
+		SET is_active = false, updated_at = CURRENT_TIMESTAMP
+		WHERE symbol = $1 AND is_active = true
+	`
+
+	result, err := DB.Exec(query, symbol)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// translateTokenError maps driver errors from token writes to package errors
+func translateTokenError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTokenNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+		return ErrTokenExists
+	}
+
+	return err
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PricePoint represents a stored price observation of a series
+type PricePoint struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	Price      float64   `json:"price"`
+	Source     string    `json:"source"`
+}
+
+// PriceSeries identifies a table of price series and the column that keys each series in it
+type PriceSeries struct {
+	table     string
+	keyColumn string
+	keyLookup string // Query resolving a series key to its key column value, empty when the key is stored as is
+}
+
+var (
+	// TokenPrices holds the ETH price history of each token, keyed by token symbol
+	TokenPrices = PriceSeries{table: "price_points", keyColumn: "token_id", keyLookup: `SELECT id FROM tokens WHERE symbol = $1`}
+	// ETHQuotePrices holds the daily ETH price in each quote currency, keyed by currency
+	ETHQuotePrices = PriceSeries{table: "eth_quote_points", keyColumn: "currency"}
+)
+
+// keyFilter returns the condition selecting the rows of the series whose key is bound to $1
+func (s PriceSeries) keyFilter() string {
+	if s.keyLookup == "" {
+		return fmt.Sprintf("%s = $1", s.keyColumn)
+	}
+	return fmt.Sprintf("%s = (%s)", s.keyColumn, s.keyLookup)
+}
+
+// resolveKey returns the key column value of a series within a transaction
+func (s PriceSeries) resolveKey(tx *sql.Tx, key string) (interface{}, error) {
+	if s.keyLookup == "" {
+		return key, nil
+	}
+
+	var value int
+	if err := tx.QueryRow(s.keyLookup, key).Scan(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// GetPoints retrieves all stored price points of a series, oldest first
+func (s PriceSeries) GetPoints(key string) ([]PricePoint, error) {
+	query := fmt.Sprintf(`
+		SELECT recorded_at, price, source
+		FROM %s
+		WHERE %s
+		ORDER BY recorded_at
+	`, s.table, s.keyFilter())
+
+	rows, err := DB.Query(query, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []PricePoint
+	for rows.Next() {
+		var point PricePoint
+		if err := rows.Scan(&point.RecordedAt, &point.Price, &point.Source); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
+
+// GetLatestTime returns the timestamp of the newest stored price point of a series.
+// The second return value is false when no price points are stored yet.
+func (s PriceSeries) GetLatestTime(key string) (time.Time, bool, error) {
+	return s.boundaryTime("MAX", key)
+}
+
+// GetEarliestTime returns the timestamp of the oldest stored price point of a series.
+// The second return value is false when no price points are stored yet.
+func (s PriceSeries) GetEarliestTime(key string) (time.Time, bool, error) {
+	return s.boundaryTime("MIN", key)
+}
+
+// boundaryTime applies MIN or MAX to the timestamps of a series
+func (s PriceSeries) boundaryTime(aggregate, key string) (time.Time, bool, error) {
+	query := fmt.Sprintf(`SELECT %s(recorded_at) FROM %s WHERE %s`, aggregate, s.table, s.keyFilter())
+
+	var boundary sql.NullTime
+	if err := DB.QueryRow(query, key).Scan(&boundary); err != nil {
+		return time.Time{}, false, err
+	}
+
+	return boundary.Time, boundary.Valid, nil
+}
+
+// ReplaceSince replaces every stored price point of a series recorded at or after since with the
+// given points. Points older than since are ignored.
+func (s PriceSeries) ReplaceSince(key string, since time.Time, points []PricePoint) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	keyValue, err := s.resolveKey(tx, key)
+	if err != nil {
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND recorded_at >= $2`, s.table, s.keyColumn)
+	if _, err := tx.Exec(deleteQuery, keyValue, since); err != nil {
+		return err
+	}
+
+	onConflict := "DO UPDATE SET price = EXCLUDED.price, source = EXCLUDED.source"
+	if err := s.insertPoints(tx, keyValue, points, onConflict, func(t time.Time) bool { return !t.Before(since) }); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertBefore stores the given points of a series recorded before the given time.
+// Points at or after before are ignored and existing points are left untouched.
+func (s PriceSeries) InsertBefore(key string, before time.Time, points []PricePoint) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	keyValue, err := s.resolveKey(tx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := s.insertPoints(tx, keyValue, points, "DO NOTHING", func(t time.Time) bool { return t.Before(before) }); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertPoints inserts the points accepted by keep, resolving conflicting timestamps with onConflict
+func (s PriceSeries) insertPoints(tx *sql.Tx, keyValue interface{}, points []PricePoint, onConflict string, keep func(time.Time) bool) error {
+	stmt, err := tx.Prepare(fmt.Sprintf(`
+		INSERT INTO %s (%s, recorded_at, price, source)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (%s, recorded_at) %s
+	`, s.table, s.keyColumn, s.keyColumn, onConflict))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, point := range points {
+		if !keep(point.RecordedAt) {
+			continue
+		}
+		if _, err := stmt.Exec(keyValue, point.RecordedAt, point.Price, point.Source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrScoringProfileNotFound is returned when no scoring profile matches the given name
+var ErrScoringProfileNotFound = errors.New("scoring profile not found")
+
+// ScoringProfile is a stored set of composite score weights keyed by component name
+type ScoringProfile struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Weights     map[string]float64 `json:"weights"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// scanScoringProfile scans a scoring profile row, decoding its JSON weights
+func scanScoringProfile(scan func(dest ...interface{}) error) (*ScoringProfile, error) {
+	var profile ScoringProfile
+	var weights []byte
+	if err := scan(&profile.Name, &profile.Description, &weights, &profile.CreatedAt, &profile.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(weights, &profile.Weights); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// GetScoringProfiles retrieves all scoring profiles ordered by name
+func GetScoringProfiles() ([]ScoringProfile, error) {
+	query := `
+		SELECT name, description, weights, created_at, updated_at
+		FROM scoring_profiles
+		ORDER BY name
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []ScoringProfile
+	for rows.Next() {
+		profile, err := scanScoringProfile(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetScoringProfile retrieves a scoring profile by name.
+// sql.ErrNoRows is returned when the profile does not exist.
+func GetScoringProfile(name string) (*ScoringProfile, error) {
+	query := `
+		SELECT name, description, weights, created_at, updated_at
+		FROM scoring_profiles
+		WHERE name = $1
+	`
+
+	return scanScoringProfile(DB.QueryRow(query, name).Scan)
+}
+
+// UpsertScoringProfile creates a scoring profile or replaces the description and weights of an existing one
+func UpsertScoringProfile(profile ScoringProfile) (*ScoringProfile, error) {
+	weights, err := json.Marshal(profile.Weights)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO scoring_profiles (name, description, weights)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET
+			description = EXCLUDED.description,
+			weights = EXCLUDED.weights,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING name, description, weights, created_at, updated_at
+	`
+
+	return scanScoringProfile(DB.QueryRow(query, profile.Name, profile.Description, weights).Scan)
+}
+
+// DeleteScoringProfile removes a scoring profile
+func DeleteScoringProfile(name string) error {
+	result, err := DB.Exec(`DELETE FROM scoring_profiles WHERE name = $1`, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrScoringProfileNotFound
+	}
+
+	return nil
+}
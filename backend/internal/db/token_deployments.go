@@ -0,0 +1,158 @@
+package db
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDeploymentNotFound is returned when a token has no deployment on the requested chain
+var ErrDeploymentNotFound = errors.New("token deployment not found")
+
+// ErrDeploymentExists is returned when the token already has a deployment on the chain or the address is registered
+var ErrDeploymentExists = errors.New("token deployment already exists")
+
+// TokenDeployment represents a bridged deployment of a token on a non-canonical chain
+type TokenDeployment struct {
+	ID              int       `json:"id"`
+	TokenID         int       `json:"token_id"`
+	Blockchain      string    `json:"blockchain"`
+	ContractAddress string    `json:"contract_address"`
+	Decimals        int       `json:"decimals"`
+	EscrowAddress   string    `json:"escrow_address"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// GetAllTokenDeployments retrieves the deployments of all active tokens
+func GetAllTokenDeployments() ([]TokenDeployment, error) {
+	query := `
+		SELECT d.id, d.token_id, d.blockchain, d.contract_address, d.decimals, COALESCE(d.escrow_address, ''), d.created_at
+		FROM token_deployments d
+		JOIN tokens t ON t.id = d.token_id
+		WHERE t.is_active = true
+		ORDER BY d.token_id, d.blockchain
+	`
+
+	rows, err := DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deployments []TokenDeployment
+	for rows.Next() {
+		var deployment TokenDeployment
+		err := rows.Scan(
+			&deployment.ID,
+			&deployment.TokenID,
+			&deployment.Blockchain,
+			&deployment.ContractAddress,
+			&deployment.Decimals,
+			&deployment.EscrowAddress,
+			&deployment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, rows.Err()
+}
+
+// GetTokenDeployments retrieves the deployments of the token with the given ID
+func GetTokenDeployments(tokenID int) ([]TokenDeployment, error) {
+	query := `
+		SELECT id, token_id, blockchain, contract_address, decimals, COALESCE(escrow_address, ''), created_at
+		FROM token_deployments
+		WHERE token_id = $1
+		ORDER BY blockchain
+	`
+
+	rows, err := DB.Query(query, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deployments []TokenDeployment
+	for rows.Next() {
+		var deployment TokenDeployment
+		err := rows.Scan(
+			&deployment.ID,
+			&deployment.TokenID,
+			&deployment.Blockchain,
+			&deployment.ContractAddress,
+			&deployment.Decimals,
+			&deployment.EscrowAddress,
+			&deployment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, rows.Err()
+}
+
+// CreateTokenDeployment inserts a deployment for the active token with the given symbol
+func CreateTokenDeployment(symbol string, deployment TokenDeployment) (*TokenDeployment, error) {
+	query := `
+		INSERT INTO token_deployments (token_id, blockchain, contract_address, decimals, escrow_address)
+		SELECT id, $2, $3, $4, NULLIF($5, '')
+		FROM tokens
+		WHERE symbol = $1 AND is_active = true
+		RETURNING id, token_id, blockchain, contract_address, decimals, COALESCE(escrow_address, ''), created_at
+	`
+
+	var created TokenDeployment
+	err := DB.QueryRow(query,
+		symbol,
+		deployment.Blockchain,
+		deployment.ContractAddress,
+		deployment.Decimals,
+		deployment.EscrowAddress,
+	).Scan(
+		&created.ID,
+		&created.TokenID,
+		&created.Blockchain,
+		&created.ContractAddress,
+		&created.Decimals,
+		&created.EscrowAddress,
+		&created.CreatedAt,
+	)
+
+	if err != nil {
+		err = translateTokenError(err)
+		if errors.Is(err, ErrTokenExists) {
+			return nil, ErrDeploymentExists
+		}
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// DeleteTokenDeployment removes the deployment of the token with the given symbol on a chain
+func DeleteTokenDeployment(symbol, blockchain string) error {
+	query := `
+		DELETE FROM token_deployments
+		USING tokens
+		WHERE tokens.id = token_deployments.token_id AND tokens.symbol = $1 AND token_deployments.blockchain = $2
+	`
+
+	result, err := DB.Exec(query, symbol, blockchain)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDeploymentNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"time"
+)
+
+// ValuationSnapshot represents a computed valuation stored at a point in time
+type ValuationSnapshot struct {
+	ID         int64     `json:"id"`
+	TokenID    int       `json:"token_id"`
+	Price      float64   `json:"price"`
+	APR        float64   `json:"apr"`
+	APRMethod  string    `json:"apr_method"`
+	Stability  float64   `json:"stability"`
+	TVL        float64   `json:"tvl"` // Supply valued in ETH
+	Supply     float64   `json:"supply"`
+	Remarks    string    `json:"remarks"`
+	ComputedAt time.Time `json:"computed_at"`
+
+	// Protocol exchange rate and the APR derived from it, nil for tokens without an adapter
+	ExchangeRate *float64 `json:"exchange_rate,omitempty"`
+	ProtocolAPR  *float64 `json:"protocol_apr,omitempty"`
+	TVLUSD       *float64 `json:"tvl_usd,omitempty"`
+	Volume24h    *float64 `json:"volume_24h,omitempty"` // In USD
+}
+
+// InsertValuationSnapshot stores a valuation snapshot for the token with the given symbol
+func InsertValuationSnapshot(symbol string, snapshot ValuationSnapshot) error {
+	query := `
+		INSERT INTO valuation_snapshots (token_id, price, apr, stability, tvl, remarks, computed_at, exchange_rate, protocol_apr, supply, tvl_usd, apr_method, volume_24h)
+		SELECT id, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		FROM tokens
+		WHERE symbol = $1
+	`
+
+	_, err := DB.Exec(query,
+		symbol,
+		snapshot.Price,
+		snapshot.APR,
+		snapshot.Stability,
+		snapshot.TVL,
+		snapshot.Remarks,
+		snapshot.ComputedAt,
+		snapshot.ExchangeRate,
+		snapshot.ProtocolAPR,
+		snapshot.Supply,
+		snapshot.TVLUSD,
+		snapshot.APRMethod,
+		snapshot.Volume24h,
+	)
+	return err
+}
+
+// GetLatestValuationSnapshot retrieves the most recent valuation snapshot for a token.
+// sql.ErrNoRows is returned when no snapshot has been stored yet.
+func GetLatestValuationSnapshot(symbol string) (*ValuationSnapshot, error) {
+	query := `
+		SELECT v.id, v.token_id, v.price, v.apr, v.stability, v.tvl, v.remarks, v.computed_at, v.exchange_rate, v.protocol_apr, v.supply, v.tvl_usd, v.apr_method, v.volume_24h
+		FROM valuation_snapshots v
+		JOIN tokens t ON t.id = v.token_id
+		WHERE t.symbol = $1
+		ORDER BY v.computed_at DESC
+		LIMIT 1
+	`
+
+	var snapshot ValuationSnapshot
+	err := DB.QueryRow(query, symbol).Scan(
+		&snapshot.ID,
+		&snapshot.TokenID,
+		&snapshot.Price,
+		&snapshot.APR,
+		&snapshot.Stability,
+		&snapshot.TVL,
+		&snapshot.Remarks,
+		&snapshot.ComputedAt,
+		&snapshot.ExchangeRate,
+		&snapshot.ProtocolAPR,
+		&snapshot.Supply,
+		&snapshot.TVLUSD,
+		&snapshot.APRMethod,
+		&snapshot.Volume24h,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// GetValuationSnapshots retrieves the valuation snapshots for a token computed between from and to, oldest first
+func GetValuationSnapshots(symbol string, from, to time.Time) ([]ValuationSnapshot, error) {
+	query := `
+		SELECT v.id, v.token_id, v.price, v.apr, v.stability, v.tvl, v.remarks, v.computed_at, v.exchange_rate, v.protocol_apr, v.supply, v.tvl_usd, v.apr_method, v.volume_24h
+		FROM valuation_snapshots v
+		JOIN tokens t ON t.id = v.token_id
+		WHERE t.symbol = $1 AND v.computed_at >= $2 AND v.computed_at <= $3
+		ORDER BY v.computed_at
+	`
+
+	rows, err := DB.Query(query, symbol, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []ValuationSnapshot
+	for rows.Next() {
+		var snapshot ValuationSnapshot
+		err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.TokenID,
+			&snapshot.Price,
+			&snapshot.APR,
+			&snapshot.Stability,
+			&snapshot.TVL,
+			&snapshot.Remarks,
+			&snapshot.ComputedAt,
+			&snapshot.ExchangeRate,
+			&snapshot.ProtocolAPR,
+			&snapshot.Supply,
+			&snapshot.TVLUSD,
+			&snapshot.APRMethod,
+			&snapshot.Volume24h,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+)
+
+// JobStatus is the state of a refresh job or of a single token within it
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// finishedJobRetention is how long finished jobs remain queryable
+const finishedJobRetention = time.Hour
+
+// ErrJobRunning is returned when a refresh job is started while another one has not finished
+var ErrJobRunning = errors.New("a refresh job is already running")
+
+// TokenProgress reports the refresh progress of a single token within a job
+type TokenProgress struct {
+	Symbol    string              `json:"symbol"`
+	Status    JobStatus           `json:"status"`
+	Completed []DataKind          `json:"completed"`
+	Errors    map[DataKind]string `json:"errors,omitempty"`
+}
+
+// Job is an on-demand refresh of selected data kinds for a set of tokens
+type Job struct {
+	ID         string          `json:"job_id"`
+	Status     JobStatus       `json:"status"`
+	Kinds      []DataKind      `json:"kinds"`
+	Tokens     []TokenProgress `json:"tokens"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// ParseDataKinds validates data kind names, returning all kinds when none are given
+func ParseDataKinds(names []string) ([]DataKind, error) {
+	if len(names) == 0 {
+		return DataKinds, nil
+	}
+
+	requested := map[DataKind]bool{}
+	for _, name := range names {
+		kind := DataKind(name)
+		if kind != PriceHistory && kind != TVL && kind != Valuation {
+			return nil, fmt.Errorf("unknown data kind %q (expected price_history, tvl or valuation)", name)
+		}
+		requested[kind] = true
+	}
+
+	// Keep dependency order so valuations are computed from refreshed inputs
+	var kinds []DataKind
+	for _, kind := range DataKinds {
+		if requested[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds, nil
+}
+
+// StartJob invalidates and recomputes the given data kinds for the given tokens in the background.
+// Only one job runs at a time; ErrJobRunning is returned while another job is unfinished.
+func (s *Scheduler) StartJob(tokens []services.Token, kinds []DataKind) (*Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		ID:        id,
+		Status:    JobPending,
+		Kinds:     kinds,
+		Tokens:    make([]TokenProgress, len(tokens)),
+		CreatedAt: time.Now(),
+	}
+	for i, token := range tokens {
+		job.Tokens[i] = TokenProgress{
+			Symbol:    token.Symbol,
+			Status:    JobPending,
+			Completed: []DataKind{},
+		}
+	}
+
+	s.jobsMu.Lock()
+	s.pruneJobs()
+	for _, existing := range s.jobs {
+		if existing.FinishedAt == nil {
+			s.jobsMu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrJobRunning, existing.ID)
+		}
+	}
+	s.jobs[id] = job
+	s.jobsMu.Unlock()
+
+	s.wg.Add(1)
+	go s.runJob(job, tokens)
+
+	return s.GetJob(id), nil
+}
+
+// GetJob returns a copy of the job with the given ID, or nil if it does not exist
+func (s *Scheduler) GetJob(id string) *Job {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	job, exists := s.jobs[id]
+	if !exists {
+		return nil
+	}
+
+	jobCopy := *job
+	jobCopy.Tokens = make([]TokenProgress, len(job.Tokens))
+	for i, progress := range job.Tokens {
+		progressCopy := progress
+		progressCopy.Completed = append([]DataKind{}, progress.Completed...)
+		if progress.Errors != nil {
+			progressCopy.Errors = make(map[DataKind]string, len(progress.Errors))
+			for kind, message := range progress.Errors {
+				progressCopy.Errors[kind] = message
+			}
+		}
+		jobCopy.Tokens[i] = progressCopy
+	}
+
+	return &jobCopy
+}
+
+// runJob processes every token of a job, recording progress as it goes
+func (s *Scheduler) runJob(job *Job, tokens []services.Token) {
+	defer s.wg.Done()
+
+	ctx := context.Background()
+	s.updateJob(func() { job.Status = JobRunning })
+
+	failed := false
+	for i := range tokens {
+		token := &tokens[i]
+		progress := &job.Tokens[i]
+		s.updateJob(func() { progress.Status = JobRunning })
+
+		for _, kind := range job.Kinds {
+			invalidateCache(ctx, kind, token.Symbol)
+
+			err := s.Refresh(ctx, kind, token)
+			s.updateJob(func() {
+				if err != nil {
+					if progress.Errors == nil {
+						progress.Errors = map[DataKind]string{}
+					}
+					progress.Errors[kind] = err.Error()
+					return
+				}
+				progress.Completed = append(progress.Completed, kind)
+			})
+		}
+
+		s.updateJob(func() {
+			progress.Status = JobCompleted
+			if len(progress.Errors) > 0 {
+				progress.Status = JobFailed
+				failed = true
+			}
+		})
+	}
+
+	status := JobCompleted
+	if failed {
+		status = JobFailed
+	}
+
+	s.updateJob(func() {
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+		job.Status = status
+	})
+
+	log.Printf("Refresh job %s finished with status %s", job.ID, status)
+}
+
+// updateJob applies a change to job state while holding the jobs lock
+func (s *Scheduler) updateJob(update func()) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	update()
+}
+
+// pruneJobs removes finished jobs past their retention period; callers must hold the jobs lock
+func (s *Scheduler) pruneJobs() {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > finishedJobRetention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// invalidateCache removes the cached copies of a data kind for a token in every quote currency
+func invalidateCache(ctx context.Context, kind DataKind, symbol string) {
+	for _, key := range services.QuoteCacheKeys(string(kind), symbol) {
+		if err := cache.Delete(ctx, key); err != nil {
+			log.Printf("Warning: failed to invalidate cache key %s: %v", key, err)
+		}
+	}
+}
+
+// newJobID generates a random job identifier
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
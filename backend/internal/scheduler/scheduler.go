@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+)
+
+// DataKind identifies a kind of precomputed data
+type DataKind string
+
+const (
+	// PriceHistory is the stored price history of each token
+	PriceHistory DataKind = "price_history"
+	// TVL is the on-chain TVL of each token
+	TVL DataKind = "tvl"
+	// Valuation is the computed valuation of each token
+	Valuation DataKind = "valuation"
+)
+
+// DataKinds lists every data kind in the order they depend on each other
+var DataKinds = []DataKind{PriceHistory, TVL, Valuation}
+
+// Config holds the refresh interval of each data kind
+type Config struct {
+	PriceHistoryInterval time.Duration
+	TVLInterval          time.Duration
+	ValuationInterval    time.Duration
+}
+
+// Scheduler periodically recomputes price history, TVL and valuations for all tokens
+// and runs on-demand refresh jobs
+type Scheduler struct {
+	tokenService     *services.TokenService
+	valuationService *services.ValuationService
+	intervals        map[DataKind]time.Duration
+
+	jobsMu sync.Mutex
+	jobs   map[string]*Job
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new refresh scheduler
+func NewScheduler(cfg Config, tokenService *services.TokenService, valuationService *services.ValuationService) *Scheduler {
+	return &Scheduler{
+		tokenService:     tokenService,
+		valuationService: valuationService,
+		intervals: map[DataKind]time.Duration{
+			PriceHistory: cfg.PriceHistoryInterval,
+			TVL:          cfg.TVLInterval,
+			Valuation:    cfg.ValuationInterval,
+		},
+		jobs: map[string]*Job{},
+	}
+}
+
+// Start runs an initial refresh of every data kind and then refreshes each kind on its own interval
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		// Refresh in dependency order first so valuations are computed from fresh inputs
+		for _, kind := range DataKinds {
+			s.RefreshAll(ctx, kind)
+		}
+
+		for _, kind := range DataKinds {
+			s.wg.Add(1)
+			go s.run(ctx, kind)
+		}
+	}()
+
+	log.Printf("Refresh scheduler started (price_history every %s, tvl every %s, valuation every %s)",
+		s.intervals[PriceHistory], s.intervals[TVL], s.intervals[Valuation])
+}
+
+// Stop stops all refresh loops and waits for running refreshes and jobs to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// run refreshes a data kind every time its interval elapses until the context is cancelled
+func (s *Scheduler) run(ctx context.Context, kind DataKind) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.intervals[kind])
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RefreshAll(ctx, kind)
+		}
+	}
+}
+
+// RefreshAll refreshes a data kind for every active token
+func (s *Scheduler) RefreshAll(ctx context.Context, kind DataKind) {
+	tokens, err := s.tokenService.GetAllTokens(ctx)
+	if err != nil {
+		log.Printf("Scheduler: failed to fetch tokens for %s refresh: %v", kind, err)
+		return
+	}
+
+	start := time.Now()
+
+	// Quote currency series are shared by all tokens, so they are synced once per price history pass
+	if kind == PriceHistory {
+		if err := s.valuationService.RefreshQuoteHistories(ctx); err != nil {
+			log.Printf("Scheduler: failed to refresh quote currency prices: %v", err)
+		}
+	}
+
+	failed := 0
+	for i := range tokens {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := s.Refresh(ctx, kind, &tokens[i]); err != nil {
+			log.Printf("Scheduler: failed to refresh %s for %s: %v", kind, tokens[i].Symbol, err)
+			failed++
+		}
+	}
+
+	log.Printf("Scheduler: refreshed %s for %d/%d tokens in %s", kind, len(tokens)-failed, len(tokens), time.Since(start).Round(time.Millisecond))
+}
+
+// Refresh recomputes a single data kind for a token
+func (s *Scheduler) Refresh(ctx context.Context, kind DataKind, token *services.Token) error {
+	switch kind {
+	case PriceHistory:
+		return s.valuationService.RefreshTokenPriceHistory(ctx, token)
+	case TVL:
+		return s.valuationService.RefreshTokenTVL(ctx, token)
+	case Valuation:
+		_, err := s.valuationService.RefreshTokenValuation(ctx, token)
+		return err
+	default:
+		return fmt.Errorf("unknown data kind: %s", kind)
+	}
+}
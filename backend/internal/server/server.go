@@ -1,138 +1,229 @@
-package server
-
-import (
-	"log"
-	"net/http"
-	"strings"
-
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/api"
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
-	_ "github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/docs" // Generated swagger docs
-	httpSwagger "github.com/swaggo/http-swagger"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
-)
-
-// Server holds all dependencies and provides HTTP server functionality
-type Server struct {
-	router          *chi.Mux
-	handler         *api.Handler
-	coingeckoClient *services.CoinGeckoClient
-	port            string
-}
-
-// Config holds server configuration
-type Config struct {
-	Port                string
-	CORSAllowedOrigins  string
-	CoinGeckoAPIKey     string
-	EthereumRPCURL      string
-}
-
-// NewServer creates a new server with all dependencies injected
-func NewServer(cfg *Config) (*Server, error) {
-	// Initialize database
-	if err := db.InitDB(); err != nil {
-		return nil, err
-	}
-
-	// Initialize Redis cache
-	if err := cache.InitRedis(); err != nil {
-		log.Printf("Failed to initialize Redis (continuing without cache): %v", err)
-	}
-
-	// Initialize CoinGecko client
-	coingeckoClient := services.NewCoinGeckoClient(cfg.CoinGeckoAPIKey)
-	log.Println("CoinGecko client initialized")
-
-	// Initialize services
-	tokenService := services.NewTokenService()
-	valuationService := services.NewValuationService(coingeckoClient)
-
-	// Initialize API handlers
-	handler := api.NewHandler(tokenService, valuationService)
-
-	// Set default port
-	port := cfg.Port
-	if port == "" {
-		port = "8080"
-	}
-
-	// Initialize router
-	r := chi.NewRouter()
-
-	server := &Server{
-		router:          r,
-		handler:         handler,
-		coingeckoClient: coingeckoClient,
-		port:            port,
-	}
-
-	// Setup middleware and routes
-	server.setupMiddleware(cfg)
-	server.setupRoutes()
-
-	return server, nil
-}
-
-// setupMiddleware configures middleware for the server
-func (s *Server) setupMiddleware(cfg *Config) {
-	// CORS middleware
-	corsOrigins := cfg.CORSAllowedOrigins
-	if corsOrigins == "" {
-		corsOrigins = "http://localhost:3000,http://127.0.0.1:3000"
-	}
-	allowedOrigins := strings.Split(corsOrigins, ",")
-
-	s.router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false,
-		MaxAge:           300,
-	}))
-
-	// Standard middleware
-	s.router.Use(middleware.Logger)
-	s.router.Use(middleware.Recoverer)
-	s.router.Use(middleware.RequestID)
-}
-
-// setupRoutes configures all API routes
-func (s *Server) setupRoutes() {
-	// Health check endpoint
-	s.router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	// Swagger UI
-	s.router.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("http://localhost:"+s.port+"/swagger/doc.json"),
-	))
-
-	// API routes
-	s.router.Route("/api", func(r chi.Router) {
-		r.Get("/tokens", s.handler.GetTokensHandler)
-		r.Get("/token/{id}/history", s.handler.GetTokenHistoryHandler)
-		r.Get("/token/{id}/valuation", s.handler.GetTokenValuationHandler)
-		r.Get("/valuations", s.handler.GetAllValuationsHandler)
-		r.Post("/cache/refresh", s.handler.RefreshCacheHandler)
-	})
-}
-
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	log.Printf("Server starting on port %s", s.port)
-	return http.ListenAndServe(":"+s.port, s.router)
-}
-
-// Close gracefully shuts down server dependencies
-func (s *Server) Close() {
-	db.CloseDB()
-	cache.CloseRedis()
-}
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/api"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/scheduler"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+	_ "github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/docs" // Generated swagger docs
+	httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+)
+
+// Server holds all dependencies and provides HTTP server functionality
+type Server struct {
+	router          *chi.Mux
+	handler         *api.Handler
+	coingeckoClient *services.CoinGeckoClient
+	scheduler       *scheduler.Scheduler
+	port            string
+	adminAPIToken   string
+	runScheduler    bool
+}
+
+// Config holds server configuration
+type Config struct {
+	Port                string
+	CORSAllowedOrigins  string
+	CoinGeckoAPIKey     string
+	RPCURLs             map[string]string // RPC endpoints keyed by blockchain name
+	AdminAPIToken       string
+	CryptoCompareAPIKey string
+	PriceSources        string // Comma-separated provider names in fallback order
+	SchedulerEnabled    bool
+	Scheduler           scheduler.Config
+}
+
+// NewServer creates a new server with all dependencies injected
+func NewServer(cfg *Config) (*Server, error) {
+	// Initialize database
+	if err := db.InitDB(); err != nil {
+		return nil, err
+	}
+
+	// Initialize Redis cache
+	if err := cache.InitRedis(); err != nil {
+		log.Printf("Failed to initialize Redis (continuing without cache): %v", err)
+	}
+
+	// Initialize CoinGecko client
+	coingeckoClient := services.NewCoinGeckoClient(cfg.CoinGeckoAPIKey)
+	log.Println("CoinGecko client initialized")
+
+	// Initialize services
+	chains := services.NewChainRegistry(cfg.RPCURLs)
+	log.Printf("Chains initialized (%s)", strings.Join(chains.Chains(), ","))
+
+	tokenService := services.NewTokenService(chains)
+	priceSource, err := newPriceSource(cfg, coingeckoClient)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Price sources initialized (%s)", priceSource.Name())
+
+	priceStore := services.NewPriceHistoryStore(priceSource)
+	quoteStore := services.NewETHQuoteStore(coingeckoClient)
+	valuationService := services.NewValuationService(priceStore, quoteStore, services.NewExchangeRateRegistry(), chains, coingeckoClient)
+
+	// Initialize refresh scheduler; when background refresh is enabled handlers only read precomputed data
+	refreshScheduler := scheduler.NewScheduler(cfg.Scheduler, tokenService, valuationService)
+	if cfg.SchedulerEnabled {
+		valuationService.UsePrecomputedData(true)
+	}
+
+	// Initialize API handlers
+	handler := api.NewHandler(tokenService, valuationService, services.NewScoringService(), refreshScheduler)
+
+	// Set default port
+	port := cfg.Port
+	if port == "" {
+		port = "8080"
+	}
+
+	// Initialize router
+	r := chi.NewRouter()
+
+	server := &Server{
+		router:          r,
+		handler:         handler,
+		coingeckoClient: coingeckoClient,
+		scheduler:       refreshScheduler,
+		port:            port,
+		adminAPIToken:   cfg.AdminAPIToken,
+		runScheduler:    cfg.SchedulerEnabled,
+	}
+
+	// Setup middleware and routes
+	server.setupMiddleware(cfg)
+	server.setupRoutes()
+
+	return server, nil
+}
+
+// newPriceSource builds a fallback price source from the configured provider order
+func newPriceSource(cfg *Config, coingeckoClient *services.CoinGeckoClient) (services.PriceSource, error) {
+	names := cfg.PriceSources
+	if names == "" {
+		names = "coingecko,defillama,cryptocompare"
+	}
+
+	var sources []services.PriceSource
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "coingecko":
+			sources = append(sources, coingeckoClient)
+		case "defillama":
+			sources = append(sources, services.NewDefiLlamaClient())
+		case "cryptocompare":
+			sources = append(sources, services.NewCryptoCompareClient(cfg.CryptoCompareAPIKey))
+		default:
+			return nil, fmt.Errorf("unknown price source: %s", name)
+		}
+	}
+
+	return services.NewFallbackPriceSource(sources...), nil
+}
+
+// setupMiddleware configures middleware for the server
+func (s *Server) setupMiddleware(cfg *Config) {
+	// CORS middleware
+	corsOrigins := cfg.CORSAllowedOrigins
+	if corsOrigins == "" {
+		corsOrigins = "http://localhost:3000,http://127.0.0.1:3000"
+	}
+	allowedOrigins := strings.Split(corsOrigins, ",")
+
+	s.router.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   allowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		ExposedHeaders:   []string{"Link"},
+		AllowCredentials: false,
+		MaxAge:           300,
+	}))
+
+	// Standard middleware
+	s.router.Use(middleware.Logger)
+	s.router.Use(middleware.Recoverer)
+	s.router.Use(middleware.RequestID)
+}
+
+// setupRoutes configures all API routes
+func (s *Server) setupRoutes() {
+	// Health check endpoint
+	s.router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	// Swagger UI
+	s.router.Get("/swagger/*", httpSwagger.Handler(
+		httpSwagger.URL("http://localhost:"+s.port+"/swagger/doc.json"),
+	))
+
+	// API routes
+	s.router.Route("/api", func(r chi.Router) {
+		r.Get("/tokens", s.handler.GetTokensHandler)
+		r.Get("/token/{id}/history", s.handler.GetTokenHistoryHandler)
+		r.Get("/compare", s.handler.CompareTokensHandler)
+		r.Get("/token/{id}/candles", s.handler.GetTokenCandlesHandler)
+		r.Get("/token/{id}/valuation", s.handler.GetTokenValuationHandler)
+		r.Get("/token/{id}/valuation/history", s.handler.GetTokenValuationHistoryHandler)
+		r.Get("/token/{id}/risk", s.handler.GetTokenRiskHandler)
+		r.Get("/token/{id}/tvl", s.handler.GetTokenTVLHandler)
+		r.Get("/token/{id}/exchange-rate", s.handler.GetTokenExchangeRateHandler)
+		r.Get("/token/{id}/premium/history", s.handler.GetTokenPremiumHistoryHandler)
+		r.Get("/valuations", s.handler.GetAllValuationsHandler)
+		r.Get("/scoring-profiles", s.handler.GetScoringProfilesHandler)
+		r.Get("/analytics/correlations", s.handler.GetCorrelationsHandler)
+		r.Get("/analytics/signal-backtest", s.handler.GetSignalBacktestHandler)
+		r.Post("/portfolio/simulate", s.handler.SimulatePortfolioHandler)
+		r.Post("/portfolio/optimize", s.handler.OptimizePortfolioHandler)
+		r.Post("/backtest", s.handler.BacktestHandler)
+		r.Post("/projection", s.handler.ProjectYieldHandler)
+
+		// Cache refresh jobs recompute data for every token, so they require the admin token too
+		r.Group(func(r chi.Router) {
+			r.Use(api.AdminAuth(s.adminAPIToken))
+			r.Post("/cache/refresh", s.handler.RefreshCacheHandler)
+			r.Get("/cache/refresh/{jobID}", s.handler.GetRefreshJobHandler)
+		})
+
+		// Admin routes (require ADMIN_API_TOKEN as a bearer token)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(api.AdminAuth(s.adminAPIToken))
+			r.Post("/tokens", s.handler.CreateTokenHandler)
+			r.Put("/tokens/{id}", s.handler.UpdateTokenHandler)
+			r.Delete("/tokens/{id}", s.handler.DeleteTokenHandler)
+			r.Post("/tokens/{id}/deployments", s.handler.CreateTokenDeploymentHandler)
+			r.Delete("/tokens/{id}/deployments/{blockchain}", s.handler.DeleteTokenDeploymentHandler)
+			r.Put("/scoring-profiles/{name}", s.handler.SaveScoringProfileHandler)
+			r.Delete("/scoring-profiles/{name}", s.handler.DeleteScoringProfileHandler)
+		})
+	})
+}
+
+// Start starts the background scheduler and the HTTP server
+func (s *Server) Start() error {
+	if s.runScheduler {
+		s.scheduler.Start()
+	}
+
+	log.Printf("Server starting on port %s", s.port)
+	return http.ListenAndServe(":"+s.port, s.router)
+}
+
+// Close gracefully shuts down server dependencies
+func (s *Server) Close() {
+	s.scheduler.Stop()
+	db.CloseDB()
+	cache.CloseRedis()
+}
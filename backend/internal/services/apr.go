@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// APR methodologies. The legacy method reports the summed change of 30-day average prices in ETH;
+// every other method reports a simple annualized rate as a fraction (0.035 = 3.5%).
+const (
+	APRMethodLegacy        = "legacy"
+	APRMethodPointToPoint  = "point_to_point"
+	APRMethodTrailing7d    = "trailing_7d"
+	APRMethodTrailing30d   = "trailing_30d"
+	APRMethodTrailing90d   = "trailing_90d"
+	APRMethodLogRegression = "log_regression"
+	APRMethodProtocolRate  = "protocol_rate"
+)
+
+// DefaultAPRMethod is the methodology used for stored and cached valuations
+const DefaultAPRMethod = APRMethodLegacy
+
+// ErrUnsupportedAPRMethod is returned for unknown APR methodologies
+var ErrUnsupportedAPRMethod = errors.New("unsupported APR method")
+
+// APRInput holds the data an APR methodology can draw on
+type APRInput struct {
+	Symbol       string
+	PriceHistory []PricePoint
+	ProtocolAPR  *float64 // Trailing protocol exchange-rate APR, nil for tokens without an adapter
+}
+
+// APRCalculator computes the APR of a token with one methodology
+type APRCalculator interface {
+	// Method returns the methodology name reported with valuations
+	Method() string
+	// Calculate returns the APR of the token
+	Calculate(input APRInput) (float64, error)
+}
+
+// aprCalculators holds every supported methodology keyed by name
+var aprCalculators = map[string]APRCalculator{
+	APRMethodLegacy:        legacyAPR{},
+	APRMethodPointToPoint:  pointToPointAPR{},
+	APRMethodTrailing7d:    trailingAPR{method: APRMethodTrailing7d, days: 7},
+	APRMethodTrailing30d:   trailingAPR{method: APRMethodTrailing30d, days: 30},
+	APRMethodTrailing90d:   trailingAPR{method: APRMethodTrailing90d, days: 90},
+	APRMethodLogRegression: logRegressionAPR{},
+	APRMethodProtocolRate:  protocolRateAPR{},
+}
+
+// APRMethods returns the names of the supported APR methodologies, sorted
+func APRMethods() []string {
+	methods := make([]string, 0, len(aprCalculators))
+	for method := range aprCalculators {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// ParseAPRMethod validates an APR methodology, defaulting to DefaultAPRMethod when empty
+func ParseAPRMethod(method string) (string, error) {
+	if method == "" {
+		return DefaultAPRMethod, nil
+	}
+
+	method = strings.ToLower(method)
+	if _, exists := aprCalculators[method]; !exists {
+		return "", fmt.Errorf("%w: %s (supported: %s)", ErrUnsupportedAPRMethod, method, strings.Join(APRMethods(), ", "))
+	}
+
+	return method, nil
+}
+
+// GetAPRCalculator returns the calculator of an APR methodology
+func GetAPRCalculator(method string) (APRCalculator, error) {
+	calculator, exists := aprCalculators[method]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAPRMethod, method)
+	}
+	return calculator, nil
+}
+
+// legacyAPR sums the differences between consecutive 30-day average prices over the last year
+type legacyAPR struct {
+	quiet bool // Skip logging the monthly averages, for bulk replays
+}
+
+func (legacyAPR) Method() string { return APRMethodLegacy }
+
+func (l legacyAPR) Calculate(input APRInput) (float64, error) {
+	return calculateLegacyAPR(sortedByTime(input.PriceHistory), input.Symbol, !l.quiet)
+}
+
+// pointToPointAPR annualizes the return between the first and last price of the history
+type pointToPointAPR struct{}
+
+func (pointToPointAPR) Method() string { return APRMethodPointToPoint }
+
+func (pointToPointAPR) Calculate(input APRInput) (float64, error) {
+	points := sortedByTime(input.PriceHistory)
+	if len(points) < 2 {
+		return 0, fmt.Errorf("insufficient price data for APR calculation")
+	}
+	return annualizedReturn(points[0], points[len(points)-1])
+}
+
+// trailingAPR annualizes the return over a trailing window ending at the latest price
+type trailingAPR struct {
+	method string
+	days   int
+}
+
+func (t trailingAPR) Method() string { return t.method }
+
+func (t trailingAPR) Calculate(input APRInput) (float64, error) {
+	points := sortedByTime(input.PriceHistory)
+	if len(points) < 2 {
+		return 0, fmt.Errorf("insufficient price data for APR calculation")
+	}
+
+	last := points[len(points)-1]
+	windowStart := last.Timestamp - int64(t.days)*24*time.Hour.Milliseconds()
+
+	// Latest point at or before the start of the window
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].Timestamp > windowStart
+	})
+	if i == 0 {
+		return 0, fmt.Errorf("insufficient price data for %d-day APR calculation", t.days)
+	}
+
+	return annualizedReturn(points[i-1], last)
+}
+
+// logRegressionAPR fits a line to the log price over time and annualizes its slope,
+// which is less sensitive to the first and last prices than a point-to-point return
+type logRegressionAPR struct{}
+
+func (logRegressionAPR) Method() string { return APRMethodLogRegression }
+
+func (logRegressionAPR) Calculate(input APRInput) (float64, error) {
+	var xs, ys []float64
+	for _, point := range sortedByTime(input.PriceHistory) {
+		if point.Price <= 0 {
+			continue
+		}
+		xs = append(xs, float64(point.Timestamp)/float64(24*time.Hour.Milliseconds()))
+		ys = append(ys, math.Log(point.Price))
+	}
+	if len(xs) < 2 {
+		return 0, fmt.Errorf("insufficient price data for APR calculation")
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var covariance, variance float64
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+		variance += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if variance == 0 {
+		return 0, fmt.Errorf("insufficient price data for APR calculation")
+	}
+
+	// Slope is the continuous daily growth rate of the price
+	return covariance / variance * 365, nil
+}
+
+// protocolRateAPR reports the trailing growth of the on-chain redemption rate
+type protocolRateAPR struct{}
+
+func (protocolRateAPR) Method() string { return APRMethodProtocolRate }
+
+func (protocolRateAPR) Calculate(input APRInput) (float64, error) {
+	if input.ProtocolAPR == nil {
+		return 0, fmt.Errorf("%w: %s", ErrNoExchangeRateAdapter, input.Symbol)
+	}
+	return *input.ProtocolAPR, nil
+}
+
+// annualizedReturn returns the simple annualized return between two price points
+func annualizedReturn(from, to PricePoint) (float64, error) {
+	days := float64(to.Timestamp-from.Timestamp) / float64(24*time.Hour.Milliseconds())
+	if from.Price <= 0 || days <= 0 {
+		return 0, fmt.Errorf("insufficient price data for APR calculation")
+	}
+	return (to.Price/from.Price - 1) * 365 / days, nil
+}
+
+// sortedByTime returns a copy of points sorted oldest first
+func sortedByTime(points []PricePoint) []PricePoint {
+	sorted := append([]PricePoint{}, points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+	return sorted
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Candle resolutions and the history interval each one aggregates
+var candleResolutions = map[string]string{
+	"1h": IntervalHourly,
+	"1d": IntervalDaily,
+	"1w": IntervalWeekly,
+}
+
+// ErrUnsupportedResolution is returned for candle resolutions other than 1h, 1d or 1w
+var ErrUnsupportedResolution = errors.New("unsupported resolution")
+
+// Candle is the open/high/low/close of the price points recorded in one bucket.
+// Buckets without points have a zero count and no prices; buckets cut by the requested range
+// or still in progress are marked partial.
+type Candle struct {
+	Timestamp int64    `json:"timestamp"` // Bucket start in milliseconds
+	Open      *float64 `json:"open"`
+	High      *float64 `json:"high"`
+	Low       *float64 `json:"low"`
+	Close     *float64 `json:"close"`
+	Count     int      `json:"count"`
+	Partial   bool     `json:"partial"`
+}
+
+// ParseResolution validates a candle resolution, defaulting to 1d when empty, and returns the
+// history interval of its buckets
+func ParseResolution(resolution string) (string, error) {
+	if resolution == "" {
+		return IntervalDaily, nil
+	}
+
+	interval, exists := candleResolutions[strings.ToLower(resolution)]
+	if !exists {
+		return "", fmt.Errorf("%w: %s (supported: 1h, 1d, 1w)", ErrUnsupportedResolution, resolution)
+	}
+
+	return interval, nil
+}
+
+// CandleResolution returns the candle resolution of a history interval
+func CandleResolution(interval string) string {
+	for resolution, resolutionInterval := range candleResolutions {
+		if resolutionInterval == interval {
+			return resolution
+		}
+	}
+	return ""
+}
+
+// DefaultCandleWindow returns the range covered by candles at an interval when no start is given
+func DefaultCandleWindow(interval string) time.Duration {
+	if interval == IntervalDaily {
+		return MaxHourlyRange
+	}
+	return DefaultHistoryWindow(interval)
+}
+
+// ValidateCandleQuery checks the range of a candle query. Daily candles aggregate intraday prices,
+// so their range is limited like hourly history.
+func ValidateCandleQuery(query HistoryQuery) error {
+	if query.Interval == IntervalDaily && query.To.Sub(query.From) > MaxHourlyRange {
+		return fmt.Errorf("%w: daily candle range must not exceed %d days", ErrInvalidHistoryRange, int(MaxHourlyRange.Hours()/24))
+	}
+	return nil
+}
+
+// GetTokenCandles aggregates the price history of a token over the range and quote of a query into
+// candles at the query interval. Hourly and daily candles are built from the intraday prices of the
+// price source, weekly candles from the stored daily prices.
+func (s *ValuationService) GetTokenCandles(ctx context.Context, symbol string, query HistoryQuery) ([]Candle, error) {
+	var points []PricePoint
+	var err error
+	switch query.Interval {
+	case IntervalHourly, IntervalDaily:
+		// Hourly and daily buckets aggregate every intraday point, so their high and low reflect moves within the bucket
+		points, err = s.priceStore.GetHourlyHistory(ctx, symbol, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get intraday price history for %s: %w", symbol, err)
+		}
+	default:
+		// Weekly buckets aggregate every daily point rather than the weekly samples
+		dailyQuery := query
+		dailyQuery.Interval = IntervalDaily
+		if points, err = s.GetTokenHistory(ctx, symbol, dailyQuery); err != nil {
+			return nil, err
+		}
+	}
+
+	return aggregateCandles(points, query.Interval, query.From, query.To, time.Now()), nil
+}
+
+// aggregateCandles groups points sorted oldest first into one candle per interval bucket between from and to
+func aggregateCandles(points []PricePoint, interval string, from, to, now time.Time) []Candle {
+	var candles []Candle
+
+	i := 0
+	for start := intervalStart(from, interval); !start.After(to); start = nextIntervalStart(start, interval) {
+		end := nextIntervalStart(start, interval)
+		candle := Candle{
+			Timestamp: start.UnixMilli(),
+			Partial:   start.Before(from) || end.After(to) || end.After(now),
+		}
+
+		for ; i < len(points) && points[i].Timestamp < end.UnixMilli(); i++ {
+			if points[i].Timestamp < start.UnixMilli() {
+				continue
+			}
+
+			price := points[i].Price
+			if candle.Count == 0 {
+				open, high, low := price, price, price
+				candle.Open, candle.High, candle.Low = &open, &high, &low
+			}
+			if price > *candle.High {
+				*candle.High = price
+			}
+			if price < *candle.Low {
+				*candle.Low = price
+			}
+			closePrice := price
+			candle.Close = &closePrice
+			candle.Count++
+		}
+
+		candles = append(candles, candle)
+	}
+
+	return candles
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnsupportedChain is returned for blockchains without a configured RPC endpoint
+var ErrUnsupportedChain = errors.New("unsupported blockchain")
+
+// DefaultRPCURLs are the public RPC endpoints used for chains without a configured RPC URL
+var DefaultRPCURLs = map[string]string{
+	"ethereum": "https://ethereum-rpc.publicnode.com",
+	"arbitrum": "https://arbitrum-one-rpc.publicnode.com",
+	"optimism": "https://optimism-rpc.publicnode.com",
+	"base":     "https://base-rpc.publicnode.com",
+	"linea":    "https://linea-rpc.publicnode.com",
+	"scroll":   "https://scroll-rpc.publicnode.com",
+}
+
+// ChainRegistry maps blockchain names to their RPC endpoints
+type ChainRegistry struct {
+	rpcURLs map[string]string
+}
+
+// NewChainRegistry creates a chain registry, filling chains missing from rpcURLs with the defaults
+func NewChainRegistry(rpcURLs map[string]string) *ChainRegistry {
+	registry := &ChainRegistry{
+		rpcURLs: map[string]string{},
+	}
+
+	for chain, rpcURL := range DefaultRPCURLs {
+		registry.rpcURLs[chain] = rpcURL
+	}
+	for chain, rpcURL := range rpcURLs {
+		if rpcURL != "" {
+			registry.rpcURLs[strings.ToLower(chain)] = rpcURL
+		}
+	}
+
+	return registry
+}
+
+// RPCURL returns the RPC endpoint for a blockchain
+func (c *ChainRegistry) RPCURL(blockchain string) (string, error) {
+	rpcURL, exists := c.rpcURLs[strings.ToLower(blockchain)]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedChain, blockchain)
+	}
+	return rpcURL, nil
+}
+
+// Chains returns the names of all supported blockchains in alphabetical order
+func (c *ChainRegistry) Chains() []string {
+	chains := make([]string, 0, len(c.rpcURLs))
+	for chain := range c.rpcURLs {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	return chains
+}
+
+// Dial connects a contract reader to the RPC endpoint of a blockchain
+func (c *ChainRegistry) Dial(blockchain string) (*TVLFetcher, error) {
+	rpcURL, err := c.RPCURL(blockchain)
+	if err != nil {
+		return nil, err
+	}
+	return NewTVLFetcher(rpcURL)
+}
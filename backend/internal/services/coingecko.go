@@ -1,120 +1,265 @@
-package services
-
-import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"time"
-)
-
-// CoinGeckoClient handles API calls to CoinGecko
-type CoinGeckoClient struct {
-	apiKey     string
-	baseURL    string
-	httpClient *http.Client
-}
-
-// PricePoint represents a single price data point
-type PricePoint struct {
-	Timestamp int64   `json:"timestamp"`
-	Price     float64 `json:"price"`
-}
-
-// PriceHistory represents the response from CoinGecko market chart API
-type PriceHistory struct {
-	Prices [][]interface{} `json:"prices"` // [[timestamp, price], ...]
-}
-
-// NewCoinGeckoClient creates a new CoinGecko API client
-func NewCoinGeckoClient(apiKey string) *CoinGeckoClient {
-	return &CoinGeckoClient{
-		apiKey:  apiKey,
-		baseURL: "https://api.coingecko.com/api/v3",
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
-}
-
-// symbolToCoinGeckoID maps our token symbols to CoinGecko coin IDs
-var symbolToCoinGeckoID = map[string]string{
-	"wstETH":  "wrapped-steth",
-	"ankrETH": "ankreth",
-	"rETH":    "rocket-pool-eth",
-	"wBETH":   "wrapped-beacon-eth",
-	"pufETH":  "pufeth",
-	"LSETH":   "liquid-staked-ethereum",
-	"RSETH":   "kelp-dao-restaked-eth",
-	"METH":    "mantle-staked-ether",
-	"CBETH":   "coinbase-wrapped-staked-eth",
-	"TETH":    "treehouse-eth",
-	"SFRXETH": "staked-frax-ether",
-	"CDCETH":  "crypto-com-staked-eth",
-	"UNIETH":  "universal-eth",
-}
-
-// GetCoinGeckoID returns the CoinGecko ID for a given symbol
-func (c *CoinGeckoClient) GetCoinGeckoID(symbol string) (string, error) {
-	id, exists := symbolToCoinGeckoID[symbol]
-	if !exists {
-		return "", fmt.Errorf("unsupported token symbol: %s", symbol)
-	}
-	return id, nil
-}
-
-// GetPriceHistory fetches 1-year price history for a token
-func (c *CoinGeckoClient) GetPriceHistory(symbol string) ([]PricePoint, error) {
-	coinID, err := c.GetCoinGeckoID(symbol)
-	if err != nil {
-		return nil, err
-	}
-
-	// CoinGecko market chart endpoint for 1 year of daily data
-	url := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=eth&days=365&interval=daily", c.baseURL, coinID)
-
-	// Add API key if available
-	if c.apiKey != "" {
-		url += "&x_cg_demo_api_key=" + c.apiKey
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("CoinGecko API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	var history PriceHistory
-	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// Convert the prices array to our PricePoint format
-	var pricePoints []PricePoint
-	for _, price := range history.Prices {
-		if len(price) >= 2 {
-			timestamp, ok1 := price[0].(float64)
-			priceValue, ok2 := price[1].(float64)
-			if ok1 && ok2 {
-				pricePoints = append(pricePoints, PricePoint{
-					Timestamp: int64(timestamp),
-					Price:     priceValue,
-				})
-			}
-		}
-	}
-
-	return pricePoints, nil
-}
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+)
+
+// CoinGeckoClient handles API calls to CoinGecko
+type CoinGeckoClient struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// PricePoint represents a single price data point
+type PricePoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Price     float64 `json:"price"`
+	Source    string  `json:"source,omitempty"` // Provider that produced the point
+}
+
+// PriceHistory represents the response from CoinGecko market chart API
+type PriceHistory struct {
+	Prices [][]interface{} `json:"prices"` // [[timestamp, price], ...]
+}
+
+// Name returns the provider name used to tag price points
+func (c *CoinGeckoClient) Name() string {
+	return "coingecko"
+}
+
+// NewCoinGeckoClient creates a new CoinGecko API client
+func NewCoinGeckoClient(apiKey string) *CoinGeckoClient {
+	return &CoinGeckoClient{
+		apiKey:  apiKey,
+		baseURL: "https://api.coingecko.com/api/v3",
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// coinGeckoPlatforms maps our blockchain names to CoinGecko asset platform IDs
+var coinGeckoPlatforms = map[string]string{
+	"ethereum": "ethereum",
+	"arbitrum": "arbitrum-one",
+	"optimism": "optimistic-ethereum",
+	"base":     "base",
+	"linea":    "linea",
+	"scroll":   "scroll",
+}
+
+// CoinContract represents the response from CoinGecko contract lookup API
+type CoinContract struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+// GetCoinGeckoID returns the CoinGecko ID for a given symbol from the token registry.
+// Tokens without a stored ID are resolved by contract address and the result is stored.
+func (c *CoinGeckoClient) GetCoinGeckoID(symbol string) (string, error) {
+	token, err := db.GetTokenBySymbol(symbol)
+	if err != nil {
+		return "", fmt.Errorf("unsupported token symbol: %s", symbol)
+	}
+
+	if token.CoinGeckoID != "" {
+		return token.CoinGeckoID, nil
+	}
+
+	id, err := c.ResolveCoinGeckoID(token.Blockchain, token.ContractAddress)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve CoinGecko ID for %s: %w", symbol, err)
+	}
+
+	if err := db.SetTokenCoinGeckoID(symbol, id); err != nil {
+		// Log store error but don't fail - the ID will be resolved again next time
+		fmt.Printf("Warning: failed to store CoinGecko ID for %s: %v\n", symbol, err)
+	}
+
+	return id, nil
+}
+
+// ResolveCoinGeckoID looks up the CoinGecko ID of a token by its contract address
+func (c *CoinGeckoClient) ResolveCoinGeckoID(blockchain, contractAddress string) (string, error) {
+	platform, exists := coinGeckoPlatforms[blockchain]
+	if !exists {
+		return "", fmt.Errorf("unsupported blockchain: %s", blockchain)
+	}
+
+	var contract CoinContract
+	url := fmt.Sprintf("%s/coins/%s/contract/%s", c.baseURL, platform, strings.ToLower(contractAddress))
+	if err := c.getJSON(url, &contract); err != nil {
+		return "", err
+	}
+
+	if contract.ID == "" {
+		return "", fmt.Errorf("no CoinGecko coin found for contract %s", contractAddress)
+	}
+
+	return contract.ID, nil
+}
+
+// GetPriceHistory fetches 1-year price history for a token
+func (c *CoinGeckoClient) GetPriceHistory(symbol string) ([]PricePoint, error) {
+	return c.GetPriceHistoryDays(symbol, 365)
+}
+
+// GetPriceHistoryDays fetches daily price history for a token covering the last given number of days
+func (c *CoinGeckoClient) GetPriceHistoryDays(symbol string, days int) ([]PricePoint, error) {
+	coinID, err := c.GetCoinGeckoID(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// CoinGecko market chart endpoint for daily data
+	url := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=eth&days=%d&interval=daily", c.baseURL, coinID, days)
+
+	var history PriceHistory
+	if err := c.getJSON(url, &history); err != nil {
+		return nil, err
+	}
+
+	// Convert the prices array to our PricePoint format
+	var pricePoints []PricePoint
+	for _, price := range history.Prices {
+		if len(price) >= 2 {
+			timestamp, ok1 := price[0].(float64)
+			priceValue, ok2 := price[1].(float64)
+			if ok1 && ok2 {
+				pricePoints = append(pricePoints, PricePoint{
+					Timestamp: int64(timestamp),
+					Price:     priceValue,
+					Source:    c.Name(),
+				})
+			}
+		}
+	}
+
+	return pricePoints, nil
+}
+
+// GetHourlyPriceHistory fetches hourly prices of a token in a quote currency between from and to.
+// CoinGecko returns hourly data for ranges of 1 to 90 days and finer data for shorter ranges.
+func (c *CoinGeckoClient) GetHourlyPriceHistory(symbol, quote string, from, to time.Time) ([]PricePoint, error) {
+	coinID, err := c.GetCoinGeckoID(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/coins/%s/market_chart/range?vs_currency=%s&from=%d&to=%d", c.baseURL, coinID, quote, from.Unix(), to.Unix())
+
+	var history PriceHistory
+	if err := c.getJSON(url, &history); err != nil {
+		return nil, err
+	}
+
+	var pricePoints []PricePoint
+	for _, price := range history.Prices {
+		if len(price) >= 2 {
+			timestamp, ok1 := price[0].(float64)
+			priceValue, ok2 := price[1].(float64)
+			if ok1 && ok2 {
+				pricePoints = append(pricePoints, PricePoint{
+					Timestamp: int64(timestamp),
+					Price:     priceValue,
+					Source:    c.Name(),
+				})
+			}
+		}
+	}
+
+	return pricePoints, nil
+}
+
+// GetTokenVolume24h fetches the 24h trading volume of a token in USD
+func (c *CoinGeckoClient) GetTokenVolume24h(symbol string) (float64, error) {
+	coinID, err := c.GetCoinGeckoID(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=usd&include_24hr_vol=true", c.baseURL, coinID)
+
+	var prices SimplePrice
+	if err := c.getJSON(url, &prices); err != nil {
+		return 0, err
+	}
+
+	volume, ok := prices[coinID]["usd_24h_vol"]
+	if !ok {
+		return 0, fmt.Errorf("no trading volume for %s", symbol)
+	}
+
+	return volume, nil
+}
+
+// getJSON performs a GET request against the CoinGecko API and decodes the JSON response
+func (c *CoinGeckoClient) getJSON(url string, target interface{}) error {
+	// Add API key if available
+	if c.apiKey != "" {
+		if strings.Contains(url, "?") {
+			url += "&x_cg_demo_api_key=" + c.apiKey
+		} else {
+			url += "?x_cg_demo_api_key=" + c.apiKey
+		}
+	}
+
+	return fetchJSON(c.httpClient, "CoinGecko", url, target)
+}
+
+// CachedTokenVolume represents a cached 24h trading volume of a token
+type CachedTokenVolume struct {
+	Volume    float64   `json:"volume"`
+	CachedAt  time.Time `json:"cached_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FetchTokenVolume fetches the 24h trading volume of a token in USD with caching
+func FetchTokenVolume(ctx context.Context, client *CoinGeckoClient, symbol string) (float64, error) {
+	cacheKey := fmt.Sprintf("volume_24h:%s", symbol)
+
+	// Try to get from cache first
+	if cachedData, err := cache.Get(ctx, cacheKey); err == nil {
+		var cached CachedTokenVolume
+		if err := json.Unmarshal([]byte(cachedData), &cached); err == nil && time.Now().Before(cached.ExpiresAt) {
+			return cached.Volume, nil
+		}
+	}
+
+	volume, err := client.GetTokenVolume24h(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	cacheDurationStr := os.Getenv("VOLUME_CACHE_DURATION")
+	cacheDuration := 30 * time.Minute
+	if cacheDurationStr != "" {
+		if parsed, err := time.ParseDuration(cacheDurationStr); err == nil {
+			cacheDuration = parsed
+		}
+	}
+
+	cached := CachedTokenVolume{
+		Volume:    volume,
+		CachedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(cacheDuration),
+	}
+	if cachedData, err := json.Marshal(cached); err == nil {
+		if err := cache.Set(ctx, cacheKey, string(cachedData), cacheDuration); err != nil {
+			fmt.Printf("Warning: failed to cache trading volume for %s: %v\n", symbol, err)
+		}
+	}
+
+	return volume, nil
+}
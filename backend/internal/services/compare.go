@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// rebaseValue is the value every comparison series starts at
+const rebaseValue = 100
+
+// TokenComparison holds the price series of several tokens aligned on shared timestamps, rebased to 100 at the
+// first timestamp every token has a price, with their pairwise price ratios and current valuations
+type TokenComparison struct {
+	Symbols    []string           `json:"symbols"`
+	Quote      string             `json:"quote"`
+	Interval   string             `json:"interval"`
+	From       time.Time          `json:"from"` // First timestamp every token has a price
+	To         time.Time          `json:"to"`
+	Timestamps []int64            `json:"timestamps"` // Interval starts in milliseconds, shared by every series
+	Series     []ComparisonSeries `json:"series"`
+	Ratios     []PriceRatio       `json:"ratios"`
+	Valuations []ValuationData    `json:"valuations"`
+}
+
+// ComparisonSeries is the price series of one token on the shared timestamps. Intervals without a price carry
+// the previous price forward.
+type ComparisonSeries struct {
+	Symbol  string    `json:"symbol"`
+	Prices  []float64 `json:"prices"`  // In the quote currency
+	Rebased []float64 `json:"rebased"` // 100 at the first timestamp
+	Filled  int       `json:"filled"`  // Intervals carried forward from an earlier price
+}
+
+// PriceRatio is the price of one token in units of another on the shared timestamps
+type PriceRatio struct {
+	Symbol   string    `json:"symbol"`
+	PricedIn string    `json:"priced_in"`
+	Values   []float64 `json:"values"`
+}
+
+// CompareTokens aligns the price histories of tokens on the intervals of a history query, rebases them to 100,
+// computes the price of each token in every later one, and attaches the valuation of each token
+func (s *ValuationService) CompareTokens(ctx context.Context, tokens []Token, query HistoryQuery, aprMethod string) (*TokenComparison, error) {
+	comparison := &TokenComparison{
+		Quote:    query.Quote,
+		Interval: query.Interval,
+		To:       query.To,
+		Ratios:   []PriceRatio{},
+	}
+
+	// Last price of each interval per token
+	prices := make([]map[int64]float64, len(tokens))
+	for i, token := range tokens {
+		comparison.Symbols = append(comparison.Symbols, token.Symbol)
+
+		priceHistory, err := s.GetTokenHistory(ctx, token.Symbol, query)
+		if err != nil {
+			return nil, err
+		}
+
+		prices[i] = map[int64]float64{}
+		for _, point := range sortedByTime(priceHistory) {
+			if point.Price <= 0 {
+				continue
+			}
+			prices[i][intervalStart(time.UnixMilli(point.Timestamp), query.Interval).UnixMilli()] = point.Price
+		}
+		if len(prices[i]) == 0 {
+			return nil, fmt.Errorf("%w for %s in the requested range", ErrInsufficientPriceData, token.Symbol)
+		}
+	}
+
+	timestamps, aligned, filled := alignPriceBuckets(prices)
+	comparison.Timestamps = timestamps
+	comparison.Series = make([]ComparisonSeries, len(aligned))
+	for i, seriesPrices := range aligned {
+		comparison.Series[i] = ComparisonSeries{
+			Symbol:  comparison.Symbols[i],
+			Prices:  seriesPrices,
+			Rebased: make([]float64, len(seriesPrices)),
+			Filled:  filled[i],
+		}
+		for t, price := range seriesPrices {
+			comparison.Series[i].Rebased[t] = rebaseValue * price / seriesPrices[0]
+		}
+	}
+	comparison.From = time.UnixMilli(comparison.Timestamps[0]).UTC()
+
+	for i := range comparison.Series {
+		for j := i + 1; j < len(comparison.Series); j++ {
+			ratio := PriceRatio{
+				Symbol:   comparison.Series[i].Symbol,
+				PricedIn: comparison.Series[j].Symbol,
+				Values:   make([]float64, len(comparison.Timestamps)),
+			}
+			for t := range comparison.Timestamps {
+				ratio.Values[t] = comparison.Series[i].Prices[t] / comparison.Series[j].Prices[t]
+			}
+			comparison.Ratios = append(comparison.Ratios, ratio)
+		}
+	}
+
+	valuations, err := s.GetAllTokenValuations(ctx, tokens, query.Quote, aprMethod)
+	if err != nil {
+		return nil, err
+	}
+	comparison.Valuations = valuations
+	if comparison.Valuations == nil {
+		comparison.Valuations = []ValuationData{}
+	}
+
+	return comparison, nil
+}
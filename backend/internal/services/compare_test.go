@@ -0,0 +1,64 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlignPriceBuckets(t *testing.T) {
+	const hourMs = int64(60 * 60 * 1000)
+	tests := []struct {
+		name       string
+		prices     []map[int64]float64
+		timestamps []int64
+		series     [][]float64
+		filled     []int
+	}{
+		{
+			name: "shared intervals",
+			prices: []map[int64]float64{
+				{0: 1, hourMs: 2},
+				{0: 4, hourMs: 2},
+			},
+			timestamps: []int64{0, hourMs},
+			series:     [][]float64{{1, 2}, {4, 2}},
+			filled:     []int{0, 0},
+		},
+		{
+			// The first token starts earlier but has no price at the shared first interval
+			name: "gap at the first interval",
+			prices: []map[int64]float64{
+				{0: 1, 3 * hourMs: 3},
+				{2 * hourMs: 10, 3 * hourMs: 20},
+			},
+			timestamps: []int64{2 * hourMs, 3 * hourMs},
+			series:     [][]float64{{1, 3}, {10, 20}},
+			filled:     []int{1, 0},
+		},
+		{
+			name: "gaps carry the previous price",
+			prices: []map[int64]float64{
+				{0: 2, 2 * hourMs: 4},
+				{0: 10, hourMs: 20, 2 * hourMs: 30},
+			},
+			timestamps: []int64{0, hourMs, 2 * hourMs},
+			series:     [][]float64{{2, 2, 4}, {10, 20, 30}},
+			filled:     []int{1, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timestamps, prices, filled := alignPriceBuckets(test.prices)
+			if !reflect.DeepEqual(timestamps, test.timestamps) {
+				t.Errorf("timestamps = %v, want %v", timestamps, test.timestamps)
+			}
+			if !reflect.DeepEqual(prices, test.series) {
+				t.Errorf("prices = %v, want %v", prices, test.series)
+			}
+			if !reflect.DeepEqual(filled, test.filled) {
+				t.Errorf("filled = %v, want %v", filled, test.filled)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minCorrelationSamples is the fewest aligned returns a correlation is reported for
+const minCorrelationSamples = 3
+
+// CorrelationMatrix holds the pairwise correlations of daily token returns. Returns are aligned by UTC day
+// and each pair uses only the days both tokens have a return for; pairs with fewer than three such days
+// have no correlation.
+type CorrelationMatrix struct {
+	Symbols  []string     `json:"symbols"`
+	Quote    string       `json:"quote"`
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Pearson  [][]*float64 `json:"pearson"`
+	Spearman [][]*float64 `json:"spearman"`
+	Samples  [][]int      `json:"samples"` // Aligned daily returns behind each pair
+}
+
+// GetReturnCorrelations computes the Pearson and Spearman correlation matrices of the daily returns of
+// tokens over the range and quote currency of a daily history query
+func (s *ValuationService) GetReturnCorrelations(ctx context.Context, symbols []string, query HistoryQuery) (*CorrelationMatrix, error) {
+	query.Interval = IntervalDaily
+
+	returns := make([]map[int64]float64, len(symbols))
+	for i, symbol := range symbols {
+		priceHistory, err := s.GetTokenHistory(ctx, symbol, query)
+		if err != nil {
+			return nil, err
+		}
+		returns[i] = dailyReturnsByDay(priceHistory)
+	}
+
+	n := len(symbols)
+	matrix := &CorrelationMatrix{
+		Symbols:  symbols,
+		Quote:    query.Quote,
+		From:     query.From,
+		To:       query.To,
+		Pearson:  make([][]*float64, n),
+		Spearman: make([][]*float64, n),
+		Samples:  make([][]int, n),
+	}
+	for i := range symbols {
+		matrix.Pearson[i] = make([]*float64, n)
+		matrix.Spearman[i] = make([]*float64, n)
+		matrix.Samples[i] = make([]int, n)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			xs, ys := alignReturns(returns[i], returns[j])
+			matrix.Samples[i][j], matrix.Samples[j][i] = len(xs), len(xs)
+			if len(xs) < minCorrelationSamples {
+				continue
+			}
+
+			if pearson, ok := pearsonCorrelation(xs, ys); ok {
+				matrix.Pearson[i][j], matrix.Pearson[j][i] = &pearson, &pearson
+			}
+			if spearman, ok := pearsonCorrelation(ranks(xs), ranks(ys)); ok {
+				matrix.Spearman[i][j], matrix.Spearman[j][i] = &spearman, &spearman
+			}
+		}
+	}
+
+	return matrix, nil
+}
+
+// dailyReturnsByDay keys the return of each UTC day by the day's start in milliseconds, using the last
+// price of each day. Days following a gap have no return rather than one spanning several days.
+func dailyReturnsByDay(points []PricePoint) map[int64]float64 {
+	closes := map[int64]float64{}
+	for _, point := range sortedByTime(points) {
+		closes[intervalStart(time.UnixMilli(point.Timestamp), IntervalDaily).UnixMilli()] = point.Price
+	}
+
+	dayMs := (24 * time.Hour).Milliseconds()
+	returns := map[int64]float64{}
+	for day, price := range closes {
+		previous, exists := closes[day-dayMs]
+		if exists && previous > 0 {
+			returns[day] = price/previous - 1
+		}
+	}
+
+	return returns
+}
+
+// alignReturns returns the returns of two tokens on the days both have one, ordered by day
+func alignReturns(a, b map[int64]float64) ([]float64, []float64) {
+	var days []int64
+	for day := range a {
+		if _, exists := b[day]; exists {
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+
+	xs := make([]float64, len(days))
+	ys := make([]float64, len(days))
+	for i, day := range days {
+		xs[i], ys[i] = a[day], b[day]
+	}
+	return xs, ys
+}
+
+// pearsonCorrelation returns the Pearson correlation of two equally long samples.
+// The second return value is false when either sample has no variance.
+func pearsonCorrelation(xs, ys []float64) (float64, bool) {
+	meanX, stdX := meanStdDev(xs)
+	meanY, stdY := meanStdDev(ys)
+	if !hasVariance(meanX, stdX) || !hasVariance(meanY, stdY) {
+		return 0, false
+	}
+
+	covariance := 0.0
+	for i := range xs {
+		covariance += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	covariance /= float64(len(xs))
+
+	// Clamp rounding errors to the valid range
+	return math.Max(-1, math.Min(1, covariance/(stdX*stdY))), true
+}
+
+// hasVariance reports whether the spread of a sample exceeds the rounding error of its mean,
+// so samples of equal values are not correlated on noise
+func hasVariance(mean, stdDev float64) bool {
+	return stdDev > 1e-12*math.Abs(mean)
+}
+
+// ranks returns the rank of each value, averaging the ranks of ties
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+
+		// Positions start..end-1 share the average of ranks start+1..end
+		rank := float64(start+end+1) / 2
+		for _, index := range order[start:end] {
+			result[index] = rank
+		}
+		start = end
+	}
+
+	return result
+}
+
+// ParseWindow parses a lookback window given in days, as "90d" or "90"
+func ParseWindow(window string) (time.Duration, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(window), "d"))
+	if err != nil || days <= 0 || days > MaxHistoryDays {
+		return 0, fmt.Errorf("invalid window: expected a number of days between 1 and %d such as 90d", MaxHistoryDays)
+	}
+
+	return time.Duration(days) * 24 * time.Hour, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []float64
+	}{
+		{name: "empty", values: []float64{}, want: []float64{}},
+		{name: "one value", values: []float64{0.3}, want: []float64{1}},
+		{name: "distinct", values: []float64{0.3, -0.1, 0.2}, want: []float64{3, 1, 2}},
+		{name: "pair of ties", values: []float64{0.1, 0.2, 0.1, 0.3}, want: []float64{1.5, 3, 1.5, 4}},
+		{name: "ties at the top", values: []float64{0.5, 0.1, 0.5, 0.5}, want: []float64{3, 1, 3, 3}},
+		{name: "all equal", values: []float64{0.2, 0.2, 0.2}, want: []float64{2, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ranks(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPearsonCorrelation(t *testing.T) {
+	tests := []struct {
+		name   string
+		xs, ys []float64
+		want   float64
+		wantOK bool
+	}{
+		{name: "empty", xs: []float64{}, ys: []float64{}, wantOK: false},
+		{name: "one sample", xs: []float64{0.1}, ys: []float64{0.2}, wantOK: false},
+		{name: "all equal returns", xs: []float64{0.1, 0.1, 0.1}, ys: []float64{0.1, 0.2, 0.3}, wantOK: false},
+		{name: "perfectly correlated", xs: []float64{1, 2, 3}, ys: []float64{2, 4, 6}, want: 1, wantOK: true},
+		{name: "perfectly anticorrelated", xs: []float64{1, 2, 3}, ys: []float64{3, 2, 1}, want: -1, wantOK: true},
+		{name: "uncorrelated", xs: []float64{1, 2, 3, 4}, ys: []float64{1, -1, -1, 1}, want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pearsonCorrelation(tt.xs, tt.ys)
+			if ok != tt.wantOK || (ok && !approxEqual(got, tt.want)) {
+				t.Errorf("pearsonCorrelation() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSpearmanCorrelationWithTies(t *testing.T) {
+	// A monotonic but nonlinear relation has a Spearman correlation of one
+	xs := []float64{0.01, 0.02, 0.03, 0.04}
+	ys := []float64{0.001, 0.008, 0.027, 0.064}
+	if got, ok := pearsonCorrelation(ranks(xs), ranks(ys)); !ok || !approxEqual(got, 1) {
+		t.Errorf("Spearman correlation = (%v, %v), want (1, true)", got, ok)
+	}
+
+	// Ranks 1.5, 1.5, 3, 4 against 1, 2, 3, 4 have a covariance of 1.125 and variances of 1.125 and 1.25
+	xs = []float64{0.01, 0.01, 0.03, 0.04}
+	ys = []float64{0.1, 0.2, 0.3, 0.4}
+	want := 1.125 / math.Sqrt(1.125*1.25)
+	if got, ok := pearsonCorrelation(ranks(xs), ranks(ys)); !ok || !approxEqual(got, want) {
+		t.Errorf("Spearman correlation with ties = (%v, %v), want (%v, true)", got, ok, want)
+	}
+}
+
+func TestDailyReturnsByDay(t *testing.T) {
+	hour := time.Hour.Milliseconds()
+	points := []PricePoint{
+		{Timestamp: 0, Price: 1},
+		{Timestamp: testDayMs + hour, Price: 1.05},
+		{Timestamp: testDayMs + 2*hour, Price: 1.1}, // Last price of the day is the close
+		{Timestamp: 3 * testDayMs, Price: 1.2},      // Follows a missing day
+	}
+
+	got := dailyReturnsByDay(points)
+	if len(got) != 1 || !approxEqual(got[testDayMs], 0.1) {
+		t.Errorf("dailyReturnsByDay() = %v, want map[%d:0.1]", got, testDayMs)
+	}
+}
+
+func TestAlignReturns(t *testing.T) {
+	a := map[int64]float64{0: 0.1, testDayMs: 0.2, 2 * testDayMs: 0.3}
+	b := map[int64]float64{testDayMs: -0.2, 2 * testDayMs: -0.3, 3 * testDayMs: -0.4}
+
+	xs, ys := alignReturns(a, b)
+	if !reflect.DeepEqual(xs, []float64{0.2, 0.3}) || !reflect.DeepEqual(ys, []float64{-0.2, -0.3}) {
+		t.Errorf("alignReturns() = (%v, %v), want ([0.2 0.3], [-0.2 -0.3])", xs, ys)
+	}
+
+	xs, ys = alignReturns(a, map[int64]float64{})
+	if len(xs) != 0 || len(ys) != 0 {
+		t.Errorf("alignReturns() with no shared days = (%v, %v), want empty", xs, ys)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CryptoCompareClient handles API calls to the CryptoCompare historical data API
+type CryptoCompareClient struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// CryptoCompareHistory represents the response from CryptoCompare daily history API
+type CryptoCompareHistory struct {
+	Response string `json:"Response"`
+	Message  string `json:"Message"`
+	Data     struct {
+		Data []struct {
+			Time  int64   `json:"time"`
+			Close float64 `json:"close"`
+		} `json:"Data"`
+	} `json:"Data"`
+}
+
+// NewCryptoCompareClient creates a new CryptoCompare API client
+func NewCryptoCompareClient(apiKey string) *CryptoCompareClient {
+	return &CryptoCompareClient{
+		apiKey:  apiKey,
+		baseURL: "https://min-api.cryptocompare.com/data/v2",
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name used to tag price points
+func (c *CryptoCompareClient) Name() string {
+	return "cryptocompare"
+}
+
+// GetPriceHistoryDays fetches daily ETH-denominated closing prices for a token
+func (c *CryptoCompareClient) GetPriceHistoryDays(symbol string, days int) ([]PricePoint, error) {
+	url := fmt.Sprintf("%s/histoday?fsym=%s&tsym=ETH&limit=%d", c.baseURL, strings.ToUpper(symbol), days)
+
+	// Add API key if available
+	if c.apiKey != "" {
+		url += "&api_key=" + c.apiKey
+	}
+
+	var history CryptoCompareHistory
+	if err := fetchJSON(c.httpClient, "CryptoCompare", url, &history); err != nil {
+		return nil, err
+	}
+
+	if history.Response != "Success" {
+		return nil, fmt.Errorf("CryptoCompare API error: %s", history.Message)
+	}
+
+	var pricePoints []PricePoint
+	for _, day := range history.Data.Data {
+		// Days before the pair was listed are returned with zero prices
+		if day.Close == 0 {
+			continue
+		}
+		pricePoints = append(pricePoints, PricePoint{
+			Timestamp: day.Time * 1000,
+			Price:     day.Close,
+			Source:    c.Name(),
+		})
+	}
+
+	return pricePoints, nil
+}
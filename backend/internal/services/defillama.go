@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+)
+
+// DefiLlamaClient handles API calls to the DefiLlama coins API
+type DefiLlamaClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// DefiLlamaChart represents the response from DefiLlama chart API
+type DefiLlamaChart struct {
+	Coins map[string]struct {
+		Symbol string `json:"symbol"`
+		Prices []struct {
+			Timestamp int64   `json:"timestamp"`
+			Price     float64 `json:"price"`
+		} `json:"prices"`
+	} `json:"coins"`
+}
+
+// defiLlamaChains maps our blockchain names to DefiLlama chain prefixes
+var defiLlamaChains = map[string]string{
+	"ethereum": "ethereum",
+	"arbitrum": "arbitrum",
+	"optimism": "optimism",
+	"base":     "base",
+	"linea":    "linea",
+	"scroll":   "scroll",
+}
+
+// defiLlamaETH is the DefiLlama coin key for ETH, used to convert USD prices to ETH
+const defiLlamaETH = "coingecko:ethereum"
+
+// NewDefiLlamaClient creates a new DefiLlama API client
+func NewDefiLlamaClient() *DefiLlamaClient {
+	return &DefiLlamaClient{
+		baseURL: "https://coins.llama.fi",
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider name used to tag price points
+func (c *DefiLlamaClient) Name() string {
+	return "defillama"
+}
+
+// GetPriceHistoryDays fetches daily USD prices for the token and ETH and converts them to ETH
+func (c *DefiLlamaClient) GetPriceHistoryDays(symbol string, days int) ([]PricePoint, error) {
+	token, err := db.GetTokenBySymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported token symbol: %s", symbol)
+	}
+
+	chain, exists := defiLlamaChains[token.Blockchain]
+	if !exists {
+		return nil, fmt.Errorf("unsupported blockchain: %s", token.Blockchain)
+	}
+	tokenKey := chain + ":" + strings.ToLower(token.ContractAddress)
+
+	start := time.Now().AddDate(0, 0, -days).Unix()
+	url := fmt.Sprintf("%s/chart/%s,%s?start=%d&span=%d&period=1d", c.baseURL, tokenKey, defiLlamaETH, start, days+1)
+
+	var chart DefiLlamaChart
+	if err := fetchJSON(c.httpClient, "DefiLlama", url, &chart); err != nil {
+		return nil, err
+	}
+
+	tokenChart, ok := chart.Coins[tokenKey]
+	if !ok {
+		return nil, fmt.Errorf("no DefiLlama prices for %s", tokenKey)
+	}
+	ethChart, ok := chart.Coins[defiLlamaETH]
+	if !ok {
+		return nil, fmt.Errorf("no DefiLlama prices for ETH")
+	}
+
+	// Match token and ETH prices by day
+	ethPrices := map[int64]float64{}
+	for _, price := range ethChart.Prices {
+		ethPrices[price.Timestamp/86400] = price.Price
+	}
+
+	var pricePoints []PricePoint
+	for _, price := range tokenChart.Prices {
+		ethPrice, ok := ethPrices[price.Timestamp/86400]
+		if !ok || ethPrice == 0 {
+			continue
+		}
+		pricePoints = append(pricePoints, PricePoint{
+			Timestamp: price.Timestamp * 1000,
+			Price:     price.Price / ethPrice,
+			Source:    c.Name(),
+		})
+	}
+
+	sort.Slice(pricePoints, func(i, j int) bool {
+		return pricePoints[i].Timestamp < pricePoints[j].Timestamp
+	})
+
+	return pricePoints, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC20Metadata represents the on-chain metadata of an ERC20 token
+type ERC20Metadata struct {
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+// FetchERC20Metadata reads name, symbol and decimals from the token contract
+func (t *TVLFetcher) FetchERC20Metadata(ctx context.Context, contractAddress string) (*ERC20Metadata, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	address := common.HexToAddress(contractAddress)
+
+	nameOutput, err := t.callView(ctx, parsedABI, address, nil, "name")
+	if err != nil {
+		return nil, err
+	}
+	name, ok := nameOutput.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected output type from name")
+	}
+
+	symbolOutput, err := t.callView(ctx, parsedABI, address, nil, "symbol")
+	if err != nil {
+		return nil, err
+	}
+	symbol, ok := symbolOutput.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected output type from symbol")
+	}
+
+	decimalsOutput, err := t.callView(ctx, parsedABI, address, nil, "decimals")
+	if err != nil {
+		return nil, err
+	}
+	decimals, ok := decimalsOutput.(uint8)
+	if !ok {
+		return nil, fmt.Errorf("unexpected output type from decimals")
+	}
+
+	return &ERC20Metadata{
+		Name:     name,
+		Symbol:   symbol,
+		Decimals: int(decimals),
+	}, nil
+}
+
+// FetchBalanceOf reads the token balance of an account, scaled by the token decimals
+func (t *TVLFetcher) FetchBalanceOf(ctx context.Context, contractAddress, account string, decimals int) (float64, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	output, err := t.callView(ctx, parsedABI, common.HexToAddress(contractAddress), nil, "balanceOf", common.HexToAddress(account))
+	if err != nil {
+		return 0, err
+	}
+
+	balance, ok := output.(*big.Int)
+	if !ok {
+		return 0, fmt.Errorf("unexpected output type from balanceOf")
+	}
+
+	balanceFloat := new(big.Float).SetInt(balance)
+	balanceFloat.Quo(balanceFloat, new(big.Float).SetFloat64(math.Pow(10, float64(decimals))))
+
+	value, _ := balanceFloat.Float64()
+	return value, nil
+}
+
+// callView calls a view function on the contract at the given block (nil for latest) and returns its first output
+func (t *TVLFetcher) callView(ctx context.Context, parsedABI abi.ABI, address common.Address, blockNumber *big.Int, method string, args ...interface{}) (interface{}, error) {
+	callData, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s call data: %w", method, err)
+	}
+
+	result, err := t.ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &address,
+		Data: callData,
+	}, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	outputs, err := parsedABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s result: %w", method, err)
+	}
+
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("no outputs from %s call", method)
+	}
+
+	return outputs[0], nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+)
+
+// SimplePrice represents the response from CoinGecko simple price API
+type SimplePrice map[string]map[string]float64
+
+// CachedETHPrice represents a cached ETH price in a fiat currency
+type CachedETHPrice struct {
+	Price     float64   `json:"price"`
+	CachedAt  time.Time `json:"cached_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GetETHPrice fetches the current price of ETH in the given currency (e.g. usd)
+func (c *CoinGeckoClient) GetETHPrice(currency string) (float64, error) {
+	currency = strings.ToLower(currency)
+	url := fmt.Sprintf("%s/simple/price?ids=ethereum&vs_currencies=%s", c.baseURL, currency)
+
+	var prices SimplePrice
+	if err := c.getJSON(url, &prices); err != nil {
+		return 0, err
+	}
+
+	price, ok := prices["ethereum"][currency]
+	if !ok || price <= 0 {
+		return 0, fmt.Errorf("no ETH price in %s", currency)
+	}
+
+	return price, nil
+}
+
+// FetchETHPrice fetches the current price of ETH in the given currency with caching
+func FetchETHPrice(ctx context.Context, client *CoinGeckoClient, currency string) (float64, error) {
+	cacheKey := fmt.Sprintf("eth_price:%s", strings.ToLower(currency))
+
+	// Try to get from cache first
+	if cachedData, err := cache.Get(ctx, cacheKey); err == nil {
+		var cached CachedETHPrice
+		if err := json.Unmarshal([]byte(cachedData), &cached); err == nil && time.Now().Before(cached.ExpiresAt) {
+			return cached.Price, nil
+		}
+	}
+
+	price, err := client.GetETHPrice(currency)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch ETH price in %s: %w", currency, err)
+	}
+
+	cacheDurationStr := os.Getenv("ETH_PRICE_CACHE_DURATION")
+	cacheDuration := 5 * time.Minute
+	if cacheDurationStr != "" {
+		if parsed, err := time.ParseDuration(cacheDurationStr); err == nil {
+			cacheDuration = parsed
+		}
+	}
+
+	cached := CachedETHPrice{
+		Price:     price,
+		CachedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(cacheDuration),
+	}
+	if cachedData, err := json.Marshal(cached); err == nil {
+		if cacheErr := cache.Set(ctx, cacheKey, string(cachedData), cacheDuration); cacheErr != nil {
+			// Log cache error but don't fail the request
+			fmt.Printf("Warning: failed to cache ETH price in %s: %v\n", currency, cacheErr)
+		}
+	}
+
+	return price, nil
+}
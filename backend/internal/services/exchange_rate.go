@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNoExchangeRateAdapter is returned for tokens without a protocol exchange-rate adapter
+var ErrNoExchangeRateAdapter = errors.New("no exchange rate adapter for token")
+
+// protocolAPRWindowDays is the trailing window used to annualize the protocol exchange rate
+const protocolAPRWindowDays = 30
+
+// blocksPerDay is the number of Ethereum mainnet blocks per day (12 second slots)
+const blocksPerDay = 7200
+
+// Exchange-rate ABI covering the protocol-specific getters and ERC-4626 convertToAssets
+const exchangeRateABI = `[{"inputs":[],"name":"stEthPerToken","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"getExchangeRate","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"exchangeRate","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"name":"shares","type":"uint256"}],"name":"convertToAssets","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// ExchangeRateData represents the on-chain redemption rate of a token
+type ExchangeRateData struct {
+	TokenSymbol string    `json:"token_symbol"`
+	Method      string    `json:"method"`
+	Rate        float64   `json:"rate"` // Underlying ETH per token
+	BlockNumber uint64    `json:"block_number"`
+	ProtocolAPR *float64  `json:"protocol_apr,omitempty"` // Trailing 30-day annualized rate growth
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// CachedExchangeRateData represents cached exchange rate data
+type CachedExchangeRateData struct {
+	Data      ExchangeRateData `json:"data"`
+	CachedAt  time.Time        `json:"cached_at"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+// ExchangeRateAdapter reads a token's protocol redemption rate on-chain
+type ExchangeRateAdapter interface {
+	// Method describes the contract call used to read the rate
+	Method() string
+	// FetchRate returns the underlying ETH per token at the given block (nil for latest)
+	FetchRate(ctx context.Context, fetcher *TVLFetcher, contractAddress string, decimals int, blockNumber *big.Int) (float64, error)
+}
+
+// getterRateAdapter reads the rate from a no-argument getter returning an 18-decimal rate
+type getterRateAdapter struct {
+	method string
+}
+
+// Method describes the contract call used to read the rate
+func (a getterRateAdapter) Method() string {
+	return a.method + "()"
+}
+
+// FetchRate calls the getter on the token contract
+func (a getterRateAdapter) FetchRate(ctx context.Context, fetcher *TVLFetcher, contractAddress string, decimals int, blockNumber *big.Int) (float64, error) {
+	return fetcher.callRate(ctx, contractAddress, blockNumber, a.method)
+}
+
+// erc4626RateAdapter reads the rate of an ERC-4626 vault as the assets backing one share
+type erc4626RateAdapter struct{}
+
+// Method describes the contract call used to read the rate
+func (a erc4626RateAdapter) Method() string {
+	return "convertToAssets(1 share)"
+}
+
+// FetchRate calls convertToAssets with one whole share
+func (a erc4626RateAdapter) FetchRate(ctx context.Context, fetcher *TVLFetcher, contractAddress string, decimals int, blockNumber *big.Int) (float64, error) {
+	oneShare := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return fetcher.callRate(ctx, contractAddress, blockNumber, "convertToAssets", oneShare)
+}
+
+// ExchangeRateRegistry maps token symbols to their protocol exchange-rate adapters
+type ExchangeRateRegistry struct {
+	adapters map[string]ExchangeRateAdapter
+}
+
+// NewExchangeRateRegistry creates a registry with adapters for the supported protocols
+func NewExchangeRateRegistry() *ExchangeRateRegistry {
+	registry := &ExchangeRateRegistry{
+		adapters: map[string]ExchangeRateAdapter{},
+	}
+
+	registry.Register("wstETH", getterRateAdapter{method: "stEthPerToken"})
+	registry.Register("rETH", getterRateAdapter{method: "getExchangeRate"})
+	registry.Register("CBETH", getterRateAdapter{method: "exchangeRate"})
+	registry.Register("wBETH", getterRateAdapter{method: "exchangeRate"})
+	registry.Register("SFRXETH", erc4626RateAdapter{})
+	registry.Register("pufETH", erc4626RateAdapter{})
+
+	return registry
+}
+
+// Register sets the exchange-rate adapter for a token symbol
+func (r *ExchangeRateRegistry) Register(symbol string, adapter ExchangeRateAdapter) {
+	r.adapters[strings.ToUpper(symbol)] = adapter
+}
+
+// Get returns the exchange-rate adapter for a token symbol
+func (r *ExchangeRateRegistry) Get(symbol string) (ExchangeRateAdapter, bool) {
+	adapter, exists := r.adapters[strings.ToUpper(symbol)]
+	return adapter, exists
+}
+
+// callRate calls a rate function on the contract and scales the 18-decimal result
+func (t *TVLFetcher) callRate(ctx context.Context, contractAddress string, blockNumber *big.Int, method string, args ...interface{}) (float64, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(exchangeRateABI))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	output, err := t.callView(ctx, parsedABI, common.HexToAddress(contractAddress), blockNumber, method, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, ok := output.(*big.Int)
+	if !ok {
+		return 0, fmt.Errorf("unexpected output type from %s", method)
+	}
+
+	rateFloat := new(big.Float).SetInt(rate)
+	rateFloat.Quo(rateFloat, new(big.Float).SetFloat64(math.Pow(10, 18)))
+
+	value, _ := rateFloat.Float64()
+	return value, nil
+}
+
+// RefreshExchangeRate reads the current and trailing exchange rate on-chain and updates the cache
+func RefreshExchangeRate(ctx context.Context, registry *ExchangeRateRegistry, symbol, contractAddress string, decimals int, rpcURL string) (*ExchangeRateData, error) {
+	adapter, exists := registry.Get(symbol)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNoExchangeRateAdapter, symbol)
+	}
+
+	fetcher, err := NewTVLFetcher(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contract reader: %w", err)
+	}
+	defer fetcher.Close()
+
+	blockNumber, err := fetcher.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	rate, err := adapter.FetchRate(ctx, fetcher, contractAddress, decimals, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rate: %w", err)
+	}
+
+	data := ExchangeRateData{
+		TokenSymbol: symbol,
+		Method:      adapter.Method(),
+		Rate:        rate,
+		BlockNumber: blockNumber,
+		LastUpdated: time.Now(),
+	}
+
+	// Historical state needs an archive node; report the rate without APR when unavailable
+	pastBlock := new(big.Int).SetUint64(blockNumber - protocolAPRWindowDays*blocksPerDay)
+	if pastRate, err := adapter.FetchRate(ctx, fetcher, contractAddress, decimals, pastBlock); err != nil {
+		fmt.Printf("Warning: failed to fetch historical exchange rate for %s: %v\n", symbol, err)
+	} else if pastRate > 0 {
+		protocolAPR := (rate/pastRate - 1) * 365 / protocolAPRWindowDays
+		data.ProtocolAPR = &protocolAPR
+	}
+
+	if cacheErr := SetCachedExchangeRate(ctx, symbol, data); cacheErr != nil {
+		// Log cache error but don't fail the request
+		fmt.Printf("Warning: failed to cache exchange rate for %s: %v\n", symbol, cacheErr)
+	}
+
+	return &data, nil
+}
+
+// FetchExchangeRate fetches exchange rate data with caching
+func FetchExchangeRate(ctx context.Context, registry *ExchangeRateRegistry, symbol, contractAddress string, decimals int, rpcURL string) (*ExchangeRateData, error) {
+	// Try to get from cache first
+	if cachedData, err := GetCachedExchangeRate(ctx, symbol); err == nil && cachedData != nil {
+		return cachedData, nil
+	}
+
+	// Cache miss - fetch from blockchain
+	return RefreshExchangeRate(ctx, registry, symbol, contractAddress, decimals, rpcURL)
+}
+
+// GetCachedExchangeRate retrieves exchange rate data from cache
+func GetCachedExchangeRate(ctx context.Context, symbol string) (*ExchangeRateData, error) {
+	cacheKey := fmt.Sprintf("exchange_rate:%s", symbol)
+
+	cachedData, err := cache.Get(ctx, cacheKey)
+	if err != nil {
+		return nil, nil // Cache miss
+	}
+
+	var cached CachedExchangeRateData
+	if err := json.Unmarshal([]byte(cachedData), &cached); err != nil {
+		return nil, nil // Invalid cache data
+	}
+
+	// Check if cache is expired
+	if time.Now().After(cached.ExpiresAt) {
+		cache.Delete(ctx, cacheKey)
+		return nil, nil
+	}
+
+	return &cached.Data, nil
+}
+
+// SetCachedExchangeRate stores exchange rate data in cache
+func SetCachedExchangeRate(ctx context.Context, symbol string, data ExchangeRateData) error {
+	cacheDurationStr := os.Getenv("EXCHANGE_RATE_CACHE_DURATION")
+	cacheDuration := 10 * time.Minute
+	if cacheDurationStr != "" {
+		if parsed, err := time.ParseDuration(cacheDurationStr); err == nil {
+			cacheDuration = parsed
+		}
+	}
+
+	cacheKey := fmt.Sprintf("exchange_rate:%s", symbol)
+
+	cached := CachedExchangeRateData{
+		Data:      data,
+		CachedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(cacheDuration),
+	}
+
+	cachedData, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+
+	return cache.Set(ctx, cacheKey, string(cachedData), cacheDuration)
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+)
+
+// Intervals price history can be reported at. Daily and weekly history is served from the stored
+// daily prices; hourly history is fetched from the price source on demand and cached.
+const (
+	IntervalHourly = "hourly"
+	IntervalDaily  = "daily"
+	IntervalWeekly = "weekly"
+)
+
+// Intervals lists the supported history intervals
+var Intervals = []string{IntervalHourly, IntervalDaily, IntervalWeekly}
+
+const (
+	// MaxHourlyRange is the longest range hourly history can be requested for
+	MaxHourlyRange = 90 * 24 * time.Hour
+	// MaxHistoryDays is the longest range in days any history can be requested for
+	MaxHistoryDays = 3650
+)
+
+var (
+	// ErrUnsupportedInterval is returned for history intervals other than hourly, daily or weekly
+	ErrUnsupportedInterval = errors.New("unsupported interval")
+	// ErrInvalidHistoryRange is returned for history ranges that are reversed, too long or start too early
+	ErrInvalidHistoryRange = errors.New("invalid history range")
+	// ErrHourlyHistoryUnavailable is returned when no price source provides hourly prices
+	ErrHourlyHistoryUnavailable = errors.New("hourly price history not available")
+)
+
+// HistoryQuery selects the range, interval and quote currency of a token's price history
+type HistoryQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	Quote    string
+}
+
+// DefaultHistoryQuery returns the daily ETH price history over the last year
+func DefaultHistoryQuery() HistoryQuery {
+	to := time.Now().UTC()
+	return HistoryQuery{
+		From:     to.Add(-DefaultHistoryWindow(IntervalDaily)),
+		To:       to,
+		Interval: IntervalDaily,
+		Quote:    QuoteETH,
+	}
+}
+
+// DefaultHistoryWindow returns the range covered by a history query at an interval when no start is given
+func DefaultHistoryWindow(interval string) time.Duration {
+	if interval == IntervalHourly {
+		return 7 * 24 * time.Hour
+	}
+	return 365 * 24 * time.Hour
+}
+
+// ParseInterval validates a history interval, defaulting to daily when empty
+func ParseInterval(interval string) (string, error) {
+	if interval == "" {
+		return IntervalDaily, nil
+	}
+
+	interval = strings.ToLower(interval)
+	for _, supported := range Intervals {
+		if interval == supported {
+			return interval, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s (supported: %s)", ErrUnsupportedInterval, interval, strings.Join(Intervals, ", "))
+}
+
+// EarliestHistoryStart returns the start of the UTC day MaxHistoryDays before now. Requested ranges must not
+// start earlier, so a request cannot make the price stores backfill arbitrarily old history.
+func EarliestHistoryStart() time.Time {
+	return intervalStart(time.Now().UTC().AddDate(0, 0, -MaxHistoryDays), IntervalDaily)
+}
+
+// Validate checks that the query range is ordered, starts within the history limit and is within the limits
+// of its interval
+func (q HistoryQuery) Validate() error {
+	if q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidHistoryRange)
+	}
+	if q.From.Before(EarliestHistoryStart()) {
+		return fmt.Errorf("%w: from must be within the last %d days", ErrInvalidHistoryRange, MaxHistoryDays)
+	}
+	if q.To.Sub(q.From) > MaxHistoryDays*24*time.Hour {
+		return fmt.Errorf("%w: range must not exceed %d days", ErrInvalidHistoryRange, MaxHistoryDays)
+	}
+	if q.Interval == IntervalHourly && q.To.Sub(q.From) > MaxHourlyRange {
+		return fmt.Errorf("%w: hourly range must not exceed %d days", ErrInvalidHistoryRange, int(MaxHourlyRange.Hours()/24))
+	}
+	return nil
+}
+
+// GetHourlyHistory returns prices of a token at hourly or finer granularity in a quote currency,
+// fetching them from the price source on a cache miss
+func (s *PriceHistoryStore) GetHourlyHistory(ctx context.Context, symbol string, query HistoryQuery) ([]PricePoint, error) {
+	// Try to get from cache first
+	if cachedData, err := GetCachedHourlyHistory(ctx, symbol, query); err == nil && cachedData != nil {
+		return cachedData, nil
+	}
+
+	source, ok := s.source.(IntradayPriceSource)
+	if !ok {
+		return nil, ErrHourlyHistoryUnavailable
+	}
+
+	data, err := source.GetHourlyPriceHistory(symbol, query.Quote, query.From, query.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hourly price history: %w", err)
+	}
+	data = filterPricePoints(data, query.From, query.To)
+
+	// Cache the result
+	if cacheErr := SetCachedHourlyHistory(ctx, symbol, query, data); cacheErr != nil {
+		fmt.Printf("Warning: failed to cache hourly price history for %s: %v\n", symbol, cacheErr)
+	}
+
+	return data, nil
+}
+
+// filterPricePoints returns the points recorded between from and to inclusive
+func filterPricePoints(points []PricePoint, from, to time.Time) []PricePoint {
+	fromMs, toMs := from.UnixMilli(), to.UnixMilli()
+
+	filtered := make([]PricePoint, 0, len(points))
+	for _, point := range points {
+		if point.Timestamp >= fromMs && point.Timestamp <= toMs {
+			filtered = append(filtered, point)
+		}
+	}
+
+	return filtered
+}
+
+// downsamplePricePoints keeps the last point of each hour or week of points sorted oldest first.
+// Daily points are returned unchanged since stored history is already daily.
+func downsamplePricePoints(points []PricePoint, interval string) []PricePoint {
+	if interval == IntervalDaily {
+		return points
+	}
+
+	sampled := make([]PricePoint, 0, len(points))
+	var lastBucket time.Time
+	for _, point := range points {
+		bucket := intervalStart(time.UnixMilli(point.Timestamp).UTC(), interval)
+		if len(sampled) > 0 && bucket.Equal(lastBucket) {
+			sampled[len(sampled)-1] = point
+			continue
+		}
+		sampled = append(sampled, point)
+		lastBucket = bucket
+	}
+
+	return sampled
+}
+
+// intervalStart returns the start of the hour, day or week (Monday) containing t, in UTC
+func intervalStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	if interval == IntervalHourly {
+		return t.Truncate(time.Hour)
+	}
+
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == IntervalDaily {
+		return day
+	}
+
+	daysSinceMonday := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -daysSinceMonday)
+}
+
+// nextIntervalStart returns the start of the interval following the one starting at start
+func nextIntervalStart(start time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHourly:
+		return start.Add(time.Hour)
+	case IntervalWeekly:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// startsAfter reports whether points sorted oldest first begin more than a day after t
+func startsAfter(points []PricePoint, t time.Time) bool {
+	return len(points) > 0 && points[0].Timestamp > t.Add(24*time.Hour).UnixMilli()
+}
+
+// backfillStart rounds the start of a read-path backfill down to the start of its month, so reads reaching
+// a few days further back each time share one fetch from the price source
+func backfillStart(from time.Time) time.Time {
+	from = from.UTC()
+	return time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// hourlyHistoryCacheKey builds the cache key of an hourly history query, rounded to whole hours
+func hourlyHistoryCacheKey(symbol string, query HistoryQuery) string {
+	return fmt.Sprintf("price_history:%s:%s:hourly:%d:%d", symbol, query.Quote,
+		query.From.Truncate(time.Hour).Unix(), query.To.Truncate(time.Hour).Unix())
+}
+
+// GetCachedHourlyHistory retrieves hourly price history for a query from cache
+func GetCachedHourlyHistory(ctx context.Context, symbol string, query HistoryQuery) ([]PricePoint, error) {
+	cacheKey := hourlyHistoryCacheKey(symbol, query)
+
+	cachedData, err := cache.Get(ctx, cacheKey)
+	if err != nil {
+		return nil, nil // Cache miss
+	}
+
+	var cached CachedPriceHistory
+	if err := json.Unmarshal([]byte(cachedData), &cached); err != nil {
+		return nil, nil // Invalid cache data
+	}
+
+	// Check if cache is expired
+	if time.Now().After(cached.ExpiresAt) {
+		cache.Delete(ctx, cacheKey)
+		return nil, nil
+	}
+
+	return cached.Data, nil
+}
+
+// SetCachedHourlyHistory stores hourly price history for a query in cache
+func SetCachedHourlyHistory(ctx context.Context, symbol string, query HistoryQuery, data []PricePoint) error {
+	cacheDurationStr := os.Getenv("HOURLY_HISTORY_CACHE_DURATION")
+	cacheDuration := 5 * time.Minute
+	if cacheDurationStr != "" {
+		if parsed, err := time.ParseDuration(cacheDurationStr); err == nil {
+			cacheDuration = parsed
+		}
+	}
+
+	cacheKey := hourlyHistoryCacheKey(symbol, query)
+
+	cached := CachedPriceHistory{
+		Symbol:    symbol,
+		Data:      data,
+		CachedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(cacheDuration),
+	}
+
+	cachedData, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+
+	return cache.Set(ctx, cacheKey, string(cachedData), cacheDuration)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHistoryQueryValidate(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name    string
+		query   HistoryQuery
+		wantErr error
+	}{
+		{name: "last year", query: HistoryQuery{From: now.AddDate(-1, 0, 0), To: now, Interval: IntervalDaily}},
+		{name: "full history", query: HistoryQuery{From: now.AddDate(0, 0, -MaxHistoryDays), To: now, Interval: IntervalDaily}},
+		{name: "reversed", query: HistoryQuery{From: now, To: now.AddDate(0, 0, -1), Interval: IntervalDaily}, wantErr: ErrInvalidHistoryRange},
+		{
+			// A short range far in the past would backfill history older than the limit
+			name:    "starts before the history limit",
+			query:   HistoryQuery{From: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2000, 12, 31, 0, 0, 0, 0, time.UTC), Interval: IntervalDaily},
+			wantErr: ErrInvalidHistoryRange,
+		},
+		{name: "hourly range too long", query: HistoryQuery{From: now.AddDate(0, 0, -91), To: now, Interval: IntervalHourly}, wantErr: ErrInvalidHistoryRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Optimization objectives
+const (
+	ObjectiveMinVariance  = "min_variance"  // Lowest volatility allocation
+	ObjectiveMeanVariance = "mean_variance" // Best trade-off of return and variance for a risk aversion, or the highest Sharpe ratio
+)
+
+const (
+	// defaultFrontierPoints is the number of risk aversions the efficient frontier is sampled at
+	defaultFrontierPoints = 20
+	// maxFrontierPoints caps the frontier sampling
+	maxFrontierPoints = 100
+	// optimizerIterations bounds the projected gradient steps of one solve
+	optimizerIterations = 5000
+)
+
+// ErrInvalidOptimization is returned when optimization parameters fail validation or no allocation satisfies them
+var ErrInvalidOptimization = errors.New("invalid optimization")
+
+// PortfolioOptimizationInput holds the objective and constraints of an allocation optimization
+type PortfolioOptimizationInput struct {
+	Objective        string
+	RiskAversion     *float64 // Mean-variance only; nil selects the highest Sharpe ratio on the frontier
+	MaxWeight        float64  // Largest weight of one token, 1 when zero
+	MinTVL           float64  // Smallest TVL in ETH a token needs to be eligible
+	ExcludeCustodial bool
+	From             time.Time
+	To               time.Time
+	FrontierPoints   int
+}
+
+// Allocation is a set of long-only weights summing to 1 with its expected annual return and volatility
+type Allocation struct {
+	Weights        map[string]float64 `json:"weights"`
+	ExpectedReturn float64            `json:"expected_return"`
+	Volatility     float64            `json:"volatility"`
+	SharpeRatio    float64            `json:"sharpe_ratio"`
+	RiskAversion   *float64           `json:"risk_aversion,omitempty"` // Null for the minimum-variance allocation
+}
+
+// PortfolioOptimization reports the recommended allocation and the efficient frontier of the eligible tokens.
+// Expected returns and covariances are annualized from the daily ETH returns of the days all eligible tokens have one.
+type PortfolioOptimization struct {
+	Objective       string             `json:"objective"`
+	From            time.Time          `json:"from"`
+	To              time.Time          `json:"to"`
+	Observations    int                `json:"observations"` // Aligned daily returns
+	BaseRate        float64            `json:"base_rate"`    // Annual rate Sharpe ratios are measured against
+	MaxWeight       float64            `json:"max_weight"`
+	Symbols         []string           `json:"symbols"`  // Eligible tokens
+	Excluded        map[string]string  `json:"excluded"` // Reason per excluded token
+	ExpectedReturns map[string]float64 `json:"expected_returns"`
+	Volatilities    map[string]float64 `json:"volatilities"`
+	Recommended     Allocation         `json:"recommended"`
+	Frontier        []Allocation       `json:"frontier"` // Ordered by volatility
+}
+
+// OptimizePortfolio solves for long-only allocations of the given tokens under a weight cap, after excluding
+// custodial tokens when requested and tokens whose TVL is below the minimum
+func (s *ValuationService) OptimizePortfolio(ctx context.Context, tokens []Token, input PortfolioOptimizationInput) (*PortfolioOptimization, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	if input.MaxWeight == 0 {
+		input.MaxWeight = 1
+	}
+	if input.FrontierPoints == 0 {
+		input.FrontierPoints = defaultFrontierPoints
+	}
+
+	result := &PortfolioOptimization{
+		Objective: input.Objective,
+		From:      input.From,
+		To:        input.To,
+		BaseRate:  BaseStakingRate(),
+		MaxWeight: input.MaxWeight,
+		Excluded:  map[string]string{},
+	}
+
+	var returns []map[int64]float64
+	for i := range tokens {
+		token := &tokens[i]
+		if input.ExcludeCustodial && token.IsCustodial {
+			result.Excluded[token.Symbol] = "custodial"
+			continue
+		}
+		if input.MinTVL > 0 {
+			valuation, err := s.GetTokenValuation(ctx, token.Symbol, token, QuoteETH, DefaultAPRMethod)
+			if err != nil {
+				result.Excluded[token.Symbol] = "TVL unavailable"
+				continue
+			}
+			if valuation.TVL < input.MinTVL {
+				result.Excluded[token.Symbol] = fmt.Sprintf("TVL %.0f ETH below minimum", valuation.TVL)
+				continue
+			}
+		}
+
+		priceHistory, err := s.GetTokenHistory(ctx, token.Symbol, HistoryQuery{From: input.From, To: input.To, Interval: IntervalDaily, Quote: QuoteETH})
+		if err != nil {
+			return nil, err
+		}
+		tokenReturns := dailyReturnsByDay(priceHistory)
+		if len(tokenReturns) < 2 {
+			result.Excluded[token.Symbol] = "insufficient price history"
+			continue
+		}
+
+		result.Symbols = append(result.Symbols, token.Symbol)
+		returns = append(returns, tokenReturns)
+	}
+
+	n := len(result.Symbols)
+	if n == 0 {
+		return nil, fmt.Errorf("%w: no token satisfies the constraints", ErrInvalidOptimization)
+	}
+	if float64(n)*input.MaxWeight < 1-1e-9 {
+		return nil, fmt.Errorf("%w: %d eligible tokens cannot be fully allocated with a max weight of %g", ErrInvalidOptimization, n, input.MaxWeight)
+	}
+
+	mean, covariance, observations := annualizedMoments(returns)
+	if observations < 2 {
+		return nil, fmt.Errorf("%w: the eligible tokens share fewer than two daily returns", ErrInsufficientPriceData)
+	}
+	result.Observations = observations
+	result.ExpectedReturns = map[string]float64{}
+	result.Volatilities = map[string]float64{}
+	for i, symbol := range result.Symbols {
+		result.ExpectedReturns[symbol] = mean[i]
+		result.Volatilities[symbol] = math.Sqrt(covariance[i][i])
+	}
+
+	newAllocation := func(weights []float64, riskAversion *float64) Allocation {
+		allocation := Allocation{Weights: map[string]float64{}, RiskAversion: riskAversion}
+		variance := 0.0
+		for i, symbol := range result.Symbols {
+			allocation.Weights[symbol] = weights[i]
+			allocation.ExpectedReturn += weights[i] * mean[i]
+			for j := range weights {
+				variance += weights[i] * covariance[i][j] * weights[j]
+			}
+		}
+		allocation.Volatility = math.Sqrt(math.Max(variance, 0))
+		if allocation.Volatility > 0 {
+			allocation.SharpeRatio = (allocation.ExpectedReturn - result.BaseRate) / allocation.Volatility
+		}
+		return allocation
+	}
+
+	minVariance := newAllocation(solveMeanVariance(mean, covariance, math.Inf(1), input.MaxWeight), nil)
+
+	// Sample risk aversions geometrically, scaled so the frontier runs from near the minimum-variance
+	// allocation to near the highest-return one whatever the magnitude of the returns
+	scale := 1.0
+	if spread, averageVariance := returnSpread(mean), averageDiagonal(covariance); spread > 0 && averageVariance > 0 {
+		scale = spread / averageVariance
+	}
+	result.Frontier = []Allocation{minVariance}
+	for k := 0; k < input.FrontierPoints; k++ {
+		exponent := 3 - 5*float64(k)/math.Max(float64(input.FrontierPoints-1), 1)
+		riskAversion := scale * math.Pow(10, exponent)
+		weights := solveMeanVariance(mean, covariance, riskAversion, input.MaxWeight)
+		result.Frontier = append(result.Frontier, newAllocation(weights, &riskAversion))
+	}
+	sort.SliceStable(result.Frontier, func(i, j int) bool { return result.Frontier[i].Volatility < result.Frontier[j].Volatility })
+	result.Frontier = uniqueAllocations(result.Frontier, result.Symbols)
+
+	switch {
+	case input.Objective == ObjectiveMinVariance:
+		result.Recommended = minVariance
+	case input.RiskAversion != nil:
+		result.Recommended = newAllocation(solveMeanVariance(mean, covariance, *input.RiskAversion, input.MaxWeight), input.RiskAversion)
+	default:
+		result.Recommended = result.Frontier[0]
+		for _, allocation := range result.Frontier {
+			if allocation.SharpeRatio > result.Recommended.SharpeRatio {
+				result.Recommended = allocation
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// validate checks the objective and constraints of an optimization
+func (input PortfolioOptimizationInput) validate() error {
+	if input.Objective != ObjectiveMinVariance && input.Objective != ObjectiveMeanVariance {
+		return fmt.Errorf("%w: unsupported objective %s (supported: min_variance, mean_variance)", ErrInvalidOptimization, input.Objective)
+	}
+	if input.RiskAversion != nil && (input.Objective != ObjectiveMeanVariance || *input.RiskAversion < 0 || math.IsNaN(*input.RiskAversion)) {
+		return fmt.Errorf("%w: risk aversion must be non-negative and only applies to mean_variance", ErrInvalidOptimization)
+	}
+	if input.MaxWeight < 0 || input.MaxWeight > 1 || math.IsNaN(input.MaxWeight) {
+		return fmt.Errorf("%w: max weight must be between 0 and 1", ErrInvalidOptimization)
+	}
+	if input.MinTVL < 0 || math.IsNaN(input.MinTVL) {
+		return fmt.Errorf("%w: min TVL must not be negative", ErrInvalidOptimization)
+	}
+	if input.FrontierPoints < 0 || input.FrontierPoints > maxFrontierPoints {
+		return fmt.Errorf("%w: frontier points must be between 1 and %d", ErrInvalidOptimization, maxFrontierPoints)
+	}
+	if !input.From.Before(input.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidOptimization)
+	}
+	return nil
+}
+
+// annualizedMoments returns the annualized mean returns and covariance matrix of daily returns over the
+// days every token has a return, and the number of such days. The covariance is the population covariance,
+// consistent with the volatility of the risk metrics.
+func annualizedMoments(returns []map[int64]float64) ([]float64, [][]float64, int) {
+	days := sharedDays(returns)
+
+	n := len(returns)
+	mean := make([]float64, n)
+	covariance := make([][]float64, n)
+	for i := range covariance {
+		covariance[i] = make([]float64, n)
+	}
+	if len(days) < 2 {
+		return mean, covariance, len(days)
+	}
+
+	for i, tokenReturns := range returns {
+		for _, day := range days {
+			mean[i] += tokenReturns[day]
+		}
+		mean[i] /= float64(len(days))
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			sum := 0.0
+			for _, day := range days {
+				sum += (returns[i][day] - mean[i]) * (returns[j][day] - mean[j])
+			}
+			covariance[i][j] = sum / float64(len(days)) * periodsPerYear
+			covariance[j][i] = covariance[i][j]
+		}
+	}
+	for i := range mean {
+		mean[i] *= periodsPerYear
+	}
+
+	return mean, covariance, len(days)
+}
+
+// solveMeanVariance maximizes mean·w - riskAversion/2 · wᵀΣw over long-only weights summing to 1 and capped at
+// maxWeight by projected gradient ascent. An infinite risk aversion minimizes the variance alone.
+func solveMeanVariance(mean []float64, covariance [][]float64, riskAversion, maxWeight float64) []float64 {
+	n := len(mean)
+	weights := projectCappedSimplex(make([]float64, n), maxWeight)
+	minVariance := math.IsInf(riskAversion, 1)
+
+	// The largest eigenvalue of Σ is at most its trace, which bounds the curvature of the objective
+	curvature := 0.0
+	for i := range covariance {
+		curvature += covariance[i][i]
+	}
+	if !minVariance {
+		curvature *= riskAversion
+	}
+	step := 1.0
+	if curvature > 0 {
+		step = 1 / curvature
+	}
+	if !minVariance && curvature == 0 {
+		// Linear objective: a single long step reaches the best vertex
+		step = 1e6 / math.Max(returnSpread(mean), 1e-12)
+	}
+
+	next := make([]float64, n)
+	for iteration := 0; iteration < optimizerIterations; iteration++ {
+		for i := range weights {
+			gradient := 0.0
+			for j := range weights {
+				gradient -= covariance[i][j] * weights[j]
+			}
+			if !minVariance {
+				gradient = mean[i] + riskAversion*gradient
+			}
+			next[i] = weights[i] + step*gradient
+		}
+		next = projectCappedSimplex(next, maxWeight)
+
+		change := 0.0
+		for i := range weights {
+			change += math.Abs(next[i] - weights[i])
+		}
+		weights, next = next, weights
+		if change < 1e-12 {
+			break
+		}
+	}
+
+	return weights
+}
+
+// projectCappedSimplex returns the closest weights to v that are between 0 and maxWeight and sum to 1.
+// The projection clips v - τ for the shift τ found by bisection. When len(v)·maxWeight < 1 no such weights
+// exist and every weight is capped; OptimizePortfolio rejects those constraints before solving.
+func projectCappedSimplex(v []float64, maxWeight float64) []float64 {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, value := range v {
+		low = math.Min(low, value-maxWeight)
+		high = math.Max(high, value)
+	}
+
+	clipped := func(tau float64) ([]float64, float64) {
+		weights := make([]float64, len(v))
+		sum := 0.0
+		for i, value := range v {
+			weights[i] = math.Max(0, math.Min(maxWeight, value-tau))
+			sum += weights[i]
+		}
+		return weights, sum
+	}
+
+	for iteration := 0; iteration < 60; iteration++ {
+		tau := (low + high) / 2
+		if _, sum := clipped(tau); sum > 1 {
+			low = tau
+		} else {
+			high = tau
+		}
+	}
+
+	weights, _ := clipped((low + high) / 2)
+	return weights
+}
+
+// uniqueAllocations drops allocations whose weights match the previous kept allocation
+func uniqueAllocations(allocations []Allocation, symbols []string) []Allocation {
+	var unique []Allocation
+	for _, allocation := range allocations {
+		if len(unique) > 0 {
+			previous := unique[len(unique)-1]
+			difference := 0.0
+			for _, symbol := range symbols {
+				difference += math.Abs(allocation.Weights[symbol] - previous.Weights[symbol])
+			}
+			if difference < 1e-4 {
+				continue
+			}
+		}
+		unique = append(unique, allocation)
+	}
+	return unique
+}
+
+// returnSpread returns the difference between the highest and lowest value
+func returnSpread(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	low, high := values[0], values[0]
+	for _, value := range values {
+		low, high = math.Min(low, value), math.Max(high, value)
+	}
+	return high - low
+}
+
+// averageDiagonal returns the average variance on the diagonal of a covariance matrix
+func averageDiagonal(covariance [][]float64) float64 {
+	if len(covariance) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for i := range covariance {
+		sum += covariance[i][i]
+	}
+	return sum / float64(len(covariance))
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+// weightsNear reports whether two weight vectors agree to within tolerance
+func weightsNear(got, want []float64, tolerance float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.IsNaN(got[i]) || math.Abs(got[i]-want[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func TestProjectCappedSimplex(t *testing.T) {
+	tests := []struct {
+		name      string
+		v         []float64
+		maxWeight float64
+		want      []float64
+	}{
+		{name: "empty", v: []float64{}, maxWeight: 1, want: []float64{}},
+		{name: "one token", v: []float64{-3}, maxWeight: 1, want: []float64{1}},
+		{name: "already feasible", v: []float64{0.2, 0.3, 0.5}, maxWeight: 1, want: []float64{0.2, 0.3, 0.5}},
+		{name: "shifts down", v: []float64{1, 1}, maxWeight: 1, want: []float64{0.5, 0.5}},
+		{name: "clips negatives", v: []float64{0.9, -0.5, 0.3}, maxWeight: 1, want: []float64{0.8, 0, 0.2}},
+		{name: "all equal", v: []float64{7, 7, 7, 7}, maxWeight: 1, want: []float64{0.25, 0.25, 0.25, 0.25}},
+		{name: "cap binds", v: []float64{0.9, 0.1, 0}, maxWeight: 0.5, want: []float64{0.5, 0.3, 0.2}},
+		{name: "cap exactly met", v: []float64{1, 0, 0}, maxWeight: 1.0 / 3, want: []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}},
+		// No weights sum to one under the cap, so every weight is capped
+		{name: "cap cannot be met", v: []float64{0.5, 0.2}, maxWeight: 0.4, want: []float64{0.4, 0.4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := projectCappedSimplex(tt.v, tt.maxWeight); !weightsNear(got, tt.want, 1e-9) {
+				t.Errorf("projectCappedSimplex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolveMeanVariance(t *testing.T) {
+	uncorrelated := [][]float64{{0.01, 0}, {0, 0.04}}
+	zero := [][]float64{{0, 0}, {0, 0}}
+
+	tests := []struct {
+		name         string
+		mean         []float64
+		covariance   [][]float64
+		riskAversion float64
+		maxWeight    float64
+		want         []float64
+	}{
+		{name: "one token", mean: []float64{0.03}, covariance: [][]float64{{0.01}}, riskAversion: math.Inf(1), maxWeight: 1, want: []float64{1}},
+		// Minimum-variance weights of uncorrelated tokens are proportional to the inverse variances
+		{name: "min variance", mean: []float64{0.03, 0.05}, covariance: uncorrelated, riskAversion: math.Inf(1), maxWeight: 1, want: []float64{0.8, 0.2}},
+		{name: "min variance capped", mean: []float64{0.03, 0.05}, covariance: uncorrelated, riskAversion: math.Inf(1), maxWeight: 0.6, want: []float64{0.6, 0.4}},
+		{name: "all equal returns", mean: []float64{0.03, 0.03}, covariance: zero, riskAversion: math.Inf(1), maxWeight: 1, want: []float64{0.5, 0.5}},
+		// Without risk the objective is linear and the best return takes as much weight as allowed
+		{name: "riskless mean variance", mean: []float64{0.05, 0.03}, covariance: zero, riskAversion: 1, maxWeight: 1, want: []float64{1, 0}},
+		{name: "riskless mean variance capped", mean: []float64{0.05, 0.03}, covariance: zero, riskAversion: 1, maxWeight: 0.7, want: []float64{0.7, 0.3}},
+		{name: "zero risk aversion", mean: []float64{0.03, 0.05}, covariance: uncorrelated, riskAversion: 0, maxWeight: 1, want: []float64{0, 1}},
+		// Maximizing 0.03w1 + 0.05w2 - (0.01w1² + 0.04w2²)/2 with w1 + w2 = 1 gives w2 = 0.6
+		{name: "mean variance", mean: []float64{0.03, 0.05}, covariance: uncorrelated, riskAversion: 1, maxWeight: 1, want: []float64{0.4, 0.6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := solveMeanVariance(tt.mean, tt.covariance, tt.riskAversion, tt.maxWeight); !weightsNear(got, tt.want, 1e-6) {
+				t.Errorf("solveMeanVariance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnualizedMoments(t *testing.T) {
+	tests := []struct {
+		name           string
+		returns        []map[int64]float64
+		wantMean       []float64
+		wantCovariance [][]float64
+		wantDays       int
+	}{
+		{
+			name:           "no shared days",
+			returns:        []map[int64]float64{{0: 0.01}, {testDayMs: 0.02}},
+			wantMean:       []float64{0, 0},
+			wantCovariance: [][]float64{{0, 0}, {0, 0}},
+			wantDays:       0,
+		},
+		{
+			name:           "one shared day",
+			returns:        []map[int64]float64{{0: 0.01, testDayMs: 0.02}, {testDayMs: 0.03}},
+			wantMean:       []float64{0, 0},
+			wantCovariance: [][]float64{{0, 0}, {0, 0}},
+			wantDays:       1,
+		},
+		{
+			name:           "all equal returns",
+			returns:        []map[int64]float64{{0: 0.001, testDayMs: 0.001, 2 * testDayMs: 0.001}},
+			wantMean:       []float64{0.001 * periodsPerYear},
+			wantCovariance: [][]float64{{0}},
+			wantDays:       3,
+		},
+		{
+			// Population covariance of (0.01, -0.01) and (0.02, -0.02) is 0.0002 per day
+			name:           "only shared days count",
+			returns:        []map[int64]float64{{0: 0.01, testDayMs: -0.01, 2 * testDayMs: 0.5}, {0: 0.02, testDayMs: -0.02}},
+			wantMean:       []float64{0, 0},
+			wantCovariance: [][]float64{{0.0001 * periodsPerYear, 0.0002 * periodsPerYear}, {0.0002 * periodsPerYear, 0.0004 * periodsPerYear}},
+			wantDays:       2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, covariance, days := annualizedMoments(tt.returns)
+			if days != tt.wantDays || !weightsNear(mean, tt.wantMean, 1e-9) {
+				t.Errorf("annualizedMoments() mean, days = (%v, %d), want (%v, %d)", mean, days, tt.wantMean, tt.wantDays)
+			}
+			for i := range tt.wantCovariance {
+				if !weightsNear(covariance[i], tt.wantCovariance[i], 1e-9) {
+					t.Errorf("annualizedMoments() covariance = %v, want %v", covariance, tt.wantCovariance)
+					break
+				}
+			}
+		})
+	}
+}
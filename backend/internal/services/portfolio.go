@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Rebalancing policies of a portfolio simulation
+const (
+	RebalanceNone      = "none"
+	RebalanceDaily     = "daily"
+	RebalanceWeekly    = "weekly"
+	RebalanceMonthly   = "monthly"
+	RebalanceQuarterly = "quarterly"
+	RebalanceThreshold = "threshold" // Whenever a weight drifts from its target by more than the threshold
+)
+
+const (
+	// defaultRebalanceThreshold is the weight drift that triggers a threshold rebalance when none is given
+	defaultRebalanceThreshold = 0.05
+	// maxPortfolioTokens is the most tokens a simulated portfolio can hold
+	maxPortfolioTokens = 20
+)
+
+// ErrInvalidPortfolio is returned when a portfolio simulation request fails validation
+var ErrInvalidPortfolio = errors.New("invalid portfolio")
+
+// PortfolioAllocation is the starting position of one token, given either as a weight or as an ETH amount.
+// All allocations of a portfolio must use the same form.
+type PortfolioAllocation struct {
+	Symbol    string   `json:"symbol" example:"wstETH"`
+	Weight    *float64 `json:"weight,omitempty" example:"0.5"`
+	AmountETH *float64 `json:"amount_eth,omitempty" example:"10"`
+}
+
+// PortfolioSimulationInput describes a portfolio to replay over stored price history
+type PortfolioSimulationInput struct {
+	Allocations        []PortfolioAllocation
+	From               time.Time
+	To                 time.Time
+	Rebalance          string
+	RebalanceThreshold float64 // Weight drift for the threshold policy, as a fraction
+	InitialValue       float64 // Starting value in ETH for weight allocations
+}
+
+// PortfolioHolding reports one token of a simulated portfolio
+type PortfolioHolding struct {
+	Symbol       string  `json:"symbol"`
+	TargetWeight float64 `json:"target_weight"`
+	FinalWeight  float64 `json:"final_weight"`
+	FinalValue   float64 `json:"final_value"` // In ETH
+	APR          float64 `json:"apr"`         // Annualized point-to-point return over the simulation
+}
+
+// PortfolioPoint is the value of a simulated portfolio at the end of a day
+type PortfolioPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"` // In ETH
+}
+
+// PortfolioSimulation is the result of replaying a portfolio over stored daily prices
+type PortfolioSimulation struct {
+	From         time.Time          `json:"from"` // First day all tokens have a price
+	To           time.Time          `json:"to"`
+	Rebalance    string             `json:"rebalance"`
+	Rebalances   int                `json:"rebalances"`
+	InitialValue float64            `json:"initial_value"` // In ETH
+	FinalValue   float64            `json:"final_value"`   // In ETH
+	TotalReturn  float64            `json:"total_return"`
+	BlendedAPR   float64            `json:"blended_apr"` // Target-weighted average of the token APRs
+	Risk         *RiskMetrics       `json:"risk"`        // Volatility, drawdown and ratios of the portfolio value
+	Holdings     []PortfolioHolding `json:"holdings"`
+	Series       []PortfolioPoint   `json:"series"`
+}
+
+// dailyPriceTable holds the daily closes of several tokens on a shared timeline.
+// Days a token has no price carry its previous close forward.
+type dailyPriceTable struct {
+	days   []int64     // Day starts in milliseconds
+	prices [][]float64 // prices[token][day]
+}
+
+// SimulatePortfolio replays a portfolio over the stored daily ETH prices of its tokens
+func (s *ValuationService) SimulatePortfolio(ctx context.Context, input PortfolioSimulationInput) (*PortfolioSimulation, error) {
+	symbols, targets, initialValue, err := validatePortfolio(input)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := s.loadDailyPriceTable(ctx, symbols, input.From, input.To)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := input.RebalanceThreshold
+	if threshold <= 0 {
+		threshold = defaultRebalanceThreshold
+	}
+
+	// Buy the target weights at the first day's prices
+	units := make([]float64, len(symbols))
+	for i := range symbols {
+		units[i] = initialValue * targets[i] / table.prices[i][0]
+	}
+
+	simulation := &PortfolioSimulation{
+		From:         time.UnixMilli(table.days[0]).UTC(),
+		To:           time.UnixMilli(table.days[len(table.days)-1]).UTC(),
+		Rebalance:    input.Rebalance,
+		InitialValue: initialValue,
+	}
+
+	values := make([]float64, len(symbols))
+	for day := range table.days {
+		total := 0.0
+		for i := range symbols {
+			values[i] = units[i] * table.prices[i][day]
+			total += values[i]
+		}
+
+		if day > 0 && total > 0 && shouldRebalance(input.Rebalance, threshold, table.days[day-1], table.days[day], values, targets, total) {
+			for i := range symbols {
+				units[i] = total * targets[i] / table.prices[i][day]
+				values[i] = total * targets[i]
+			}
+			simulation.Rebalances++
+		}
+
+		simulation.Series = append(simulation.Series, PortfolioPoint{Timestamp: table.days[day], Value: total})
+	}
+
+	simulation.FinalValue = simulation.Series[len(simulation.Series)-1].Value
+	simulation.TotalReturn = simulation.FinalValue/initialValue - 1
+
+	for i, symbol := range symbols {
+		first := PricePoint{Timestamp: table.days[0], Price: table.prices[i][0]}
+		last := PricePoint{Timestamp: table.days[len(table.days)-1], Price: table.prices[i][len(table.days)-1]}
+		apr, _ := annualizedReturn(first, last)
+
+		holding := PortfolioHolding{
+			Symbol:       symbol,
+			TargetWeight: targets[i],
+			FinalValue:   values[i],
+			APR:          apr,
+		}
+		if simulation.FinalValue > 0 {
+			holding.FinalWeight = values[i] / simulation.FinalValue
+		}
+		simulation.BlendedAPR += targets[i] * apr
+		simulation.Holdings = append(simulation.Holdings, holding)
+	}
+
+	valuePoints := make([]PricePoint, len(simulation.Series))
+	for i, point := range simulation.Series {
+		valuePoints[i] = PricePoint{Timestamp: point.Timestamp, Price: point.Value}
+	}
+	if risk, err := CalculateRiskMetrics(valuePoints, BaseStakingRate()); err == nil {
+		risk.Quote = QuoteETH
+		risk.From, risk.To = simulation.From, simulation.To
+		simulation.Risk = risk
+	}
+
+	return simulation, nil
+}
+
+// validatePortfolio checks a simulation request and returns its symbols, normalized target weights and
+// starting value in ETH
+func validatePortfolio(input PortfolioSimulationInput) ([]string, []float64, float64, error) {
+	if !input.From.Before(input.To) {
+		return nil, nil, 0, fmt.Errorf("%w: start date must be before end date", ErrInvalidPortfolio)
+	}
+
+	switch input.Rebalance {
+	case RebalanceNone, RebalanceDaily, RebalanceWeekly, RebalanceMonthly, RebalanceQuarterly, RebalanceThreshold:
+	default:
+		return nil, nil, 0, fmt.Errorf("%w: unsupported rebalance policy %s (supported: none, daily, weekly, monthly, quarterly, threshold)", ErrInvalidPortfolio, input.Rebalance)
+	}
+	if input.RebalanceThreshold < 0 || input.RebalanceThreshold >= 1 {
+		return nil, nil, 0, fmt.Errorf("%w: rebalance threshold must be between 0 and 1", ErrInvalidPortfolio)
+	}
+
+	symbols, targets, total, byAmount, err := normalizeAllocations(input.Allocations)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	initialValue := total
+	if !byAmount {
+		initialValue = input.InitialValue
+		if initialValue == 0 {
+			initialValue = 1
+		}
+		if initialValue < 0 || math.IsNaN(initialValue) || math.IsInf(initialValue, 0) {
+			return nil, nil, 0, fmt.Errorf("%w: initial value must be positive", ErrInvalidPortfolio)
+		}
+	}
+
+	return symbols, targets, initialValue, nil
+}
+
+// normalizeAllocations validates allocations and returns their symbols, weights normalized to sum to 1 and the
+// sum of the given weights or ETH amounts, and whether they were given as ETH amounts
+func normalizeAllocations(allocations []PortfolioAllocation) ([]string, []float64, float64, bool, error) {
+	if len(allocations) == 0 || len(allocations) > maxPortfolioTokens {
+		return nil, nil, 0, false, fmt.Errorf("%w: expected between 1 and %d allocations", ErrInvalidPortfolio, maxPortfolioTokens)
+	}
+
+	byAmount := allocations[0].AmountETH != nil
+	symbols := make([]string, len(allocations))
+	amounts := make([]float64, len(allocations))
+	seen := map[string]bool{}
+	total := 0.0
+	for i, allocation := range allocations {
+		if seen[allocation.Symbol] {
+			return nil, nil, 0, false, fmt.Errorf("%w: %s is allocated more than once", ErrInvalidPortfolio, allocation.Symbol)
+		}
+		seen[allocation.Symbol] = true
+
+		amount := allocation.Weight
+		if byAmount {
+			amount = allocation.AmountETH
+		}
+		if amount == nil || (allocation.Weight != nil && allocation.AmountETH != nil) {
+			return nil, nil, 0, false, fmt.Errorf("%w: every allocation needs either a weight or an ETH amount, not both", ErrInvalidPortfolio)
+		}
+		if *amount < 0 || math.IsNaN(*amount) || math.IsInf(*amount, 0) {
+			return nil, nil, 0, false, fmt.Errorf("%w: allocation of %s must be a non-negative number", ErrInvalidPortfolio, allocation.Symbol)
+		}
+
+		symbols[i] = allocation.Symbol
+		amounts[i] = *amount
+		total += *amount
+	}
+	if total <= 0 {
+		return nil, nil, 0, false, fmt.Errorf("%w: allocations must not all be zero", ErrInvalidPortfolio)
+	}
+
+	weights := make([]float64, len(amounts))
+	for i, amount := range amounts {
+		weights[i] = amount / total
+	}
+
+	return symbols, weights, total, byAmount, nil
+}
+
+// shouldRebalance reports whether a policy rebalances on a day given the previous day and the current holdings
+func shouldRebalance(policy string, threshold float64, previousDay, day int64, values, targets []float64, total float64) bool {
+	previous, current := time.UnixMilli(previousDay).UTC(), time.UnixMilli(day).UTC()
+
+	switch policy {
+	case RebalanceDaily:
+		return true
+	case RebalanceWeekly:
+		return !intervalStart(previous, IntervalWeekly).Equal(intervalStart(current, IntervalWeekly))
+	case RebalanceMonthly:
+		return previous.Month() != current.Month() || previous.Year() != current.Year()
+	case RebalanceQuarterly:
+		return getQuarter(previous) != getQuarter(current) || previous.Year() != current.Year()
+	case RebalanceThreshold:
+		for i := range values {
+			if math.Abs(values[i]/total-targets[i]) > threshold {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// loadDailyPriceTable aligns the daily ETH closes of tokens between from and to
+func (s *ValuationService) loadDailyPriceTable(ctx context.Context, symbols []string, from, to time.Time) (*dailyPriceTable, error) {
+	closes := make([]map[int64]float64, len(symbols))
+	for i, symbol := range symbols {
+		priceHistory, err := s.GetTokenHistory(ctx, symbol, HistoryQuery{From: from, To: to, Interval: IntervalDaily, Quote: QuoteETH})
+		if err != nil {
+			return nil, err
+		}
+
+		closes[i] = map[int64]float64{}
+		for _, point := range priceHistory {
+			if point.Price <= 0 {
+				continue
+			}
+			closes[i][intervalStart(time.UnixMilli(point.Timestamp), IntervalDaily).UnixMilli()] = point.Price
+		}
+		if len(closes[i]) == 0 {
+			return nil, fmt.Errorf("%w for %s in the requested range", ErrInsufficientPriceData, symbol)
+		}
+	}
+
+	return alignDailyCloses(closes)
+}
+
+// alignDailyCloses builds a price table from the closes of each token keyed by day
+func alignDailyCloses(closes []map[int64]float64) (*dailyPriceTable, error) {
+	days, prices, _ := alignPriceBuckets(closes)
+	if len(days) < 2 {
+		return nil, fmt.Errorf("%w: the tokens share fewer than two days of prices", ErrInsufficientPriceData)
+	}
+
+	return &dailyPriceTable{days: days, prices: prices}, nil
+}
+
+// alignPriceBuckets aligns the prices of tokens keyed by interval start (hour, day or week) on a shared
+// timeline. The timeline holds every bucket from the first one every token has a price history at. A token
+// without a price in a bucket of the timeline, including the first, carries its latest earlier price
+// forward; filled counts these buckets per token. Every token must have at least one price.
+func alignPriceBuckets(prices []map[int64]float64) ([]int64, [][]float64, []int) {
+	start := int64(math.MinInt64)
+	seen := map[int64]bool{}
+	for _, tokenPrices := range prices {
+		first := int64(math.MaxInt64)
+		for bucket := range tokenPrices {
+			seen[bucket] = true
+			if bucket < first {
+				first = bucket
+			}
+		}
+		if first > start {
+			start = first
+		}
+	}
+
+	var buckets []int64
+	for bucket := range seen {
+		if bucket >= start {
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	aligned := make([][]float64, len(prices))
+	filled := make([]int, len(prices))
+	for i, tokenPrices := range prices {
+		// Every history starts at or before the timeline, so each token has a price to carry into it
+		price, latest := 0.0, int64(math.MinInt64)
+		for bucket, bucketPrice := range tokenPrices {
+			if bucket <= start && bucket > latest {
+				price, latest = bucketPrice, bucket
+			}
+		}
+
+		aligned[i] = make([]float64, len(buckets))
+		for b, bucket := range buckets {
+			if bucketPrice, exists := tokenPrices[bucket]; exists {
+				price = bucketPrice
+			} else {
+				filled[i]++
+			}
+			aligned[i][b] = price
+		}
+	}
+
+	return buckets, aligned, filled
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testDayMs = int64(24 * 60 * 60 * 1000)
+
+func TestAlignDailyCloses(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []map[int64]float64
+		days   []int64
+		prices [][]float64
+	}{
+		{
+			name: "shared days",
+			closes: []map[int64]float64{
+				{0: 1, testDayMs: 2, 2 * testDayMs: 3},
+				{0: 10, testDayMs: 20, 2 * testDayMs: 30},
+			},
+			days:   []int64{0, testDayMs, 2 * testDayMs},
+			prices: [][]float64{{1, 2, 3}, {10, 20, 30}},
+		},
+		{
+			name: "later history sets the start",
+			closes: []map[int64]float64{
+				{0: 1, testDayMs: 2, 2 * testDayMs: 3},
+				{testDayMs: 20, 2 * testDayMs: 30},
+			},
+			days:   []int64{testDayMs, 2 * testDayMs},
+			prices: [][]float64{{2, 3}, {20, 30}},
+		},
+		{
+			// The first token starts earlier but has no close on the shared start day
+			name: "gap on the start day",
+			closes: []map[int64]float64{
+				{0: 1, 3 * testDayMs: 4},
+				{2 * testDayMs: 20, 3 * testDayMs: 30},
+			},
+			days:   []int64{2 * testDayMs, 3 * testDayMs},
+			prices: [][]float64{{1, 4}, {20, 30}},
+		},
+		{
+			name: "gaps carry the previous close",
+			closes: []map[int64]float64{
+				{0: 1, 3 * testDayMs: 4},
+				{0: 10, testDayMs: 20, 2 * testDayMs: 30},
+			},
+			days:   []int64{0, testDayMs, 2 * testDayMs, 3 * testDayMs},
+			prices: [][]float64{{1, 1, 1, 4}, {10, 20, 30, 30}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table, err := alignDailyCloses(test.closes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(table.days, test.days) {
+				t.Errorf("days = %v, want %v", table.days, test.days)
+			}
+			if !reflect.DeepEqual(table.prices, test.prices) {
+				t.Errorf("prices = %v, want %v", table.prices, test.prices)
+			}
+		})
+	}
+}
+
+func TestAlignDailyClosesInsufficientData(t *testing.T) {
+	closes := []map[int64]float64{
+		{0: 1, testDayMs: 2},
+		{testDayMs: 20},
+	}
+	if _, err := alignDailyCloses(closes); !errors.Is(err, ErrInsufficientPriceData) {
+		t.Errorf("error = %v, want %v", err, ErrInsufficientPriceData)
+	}
+}
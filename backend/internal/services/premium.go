@@ -0,0 +1,18 @@
+package services
+
+// PremiumPoint represents the market premium or discount to NAV at a point in time
+type PremiumPoint struct {
+	Timestamp    int64   `json:"timestamp"`     // Unix timestamp in milliseconds
+	Price        float64 `json:"price"`         // Market price in ETH
+	ExchangeRate float64 `json:"exchange_rate"` // Protocol redemption rate in ETH
+	PremiumBps   float64 `json:"premium_bps"`   // Positive for a premium, negative for a discount
+}
+
+// CalculatePremiumBps returns how far the market price trades from the redemption rate in basis points.
+// The second return value is false when the rate is not usable.
+func CalculatePremiumBps(price, exchangeRate float64) (float64, bool) {
+	if exchangeRate <= 0 || price <= 0 {
+		return 0, false
+	}
+	return (price/exchangeRate - 1) * 10000, true
+}
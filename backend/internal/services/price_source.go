@@ -0,0 +1,126 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PriceSource provides ETH-denominated daily price history for tokens
+type PriceSource interface {
+	// Name returns the provider name used to tag price points
+	Name() string
+	// GetPriceHistoryDays fetches daily price history covering the last given number of days
+	GetPriceHistoryDays(symbol string, days int) ([]PricePoint, error)
+}
+
+// IntradayPriceSource is implemented by price sources that also provide hourly prices for recent ranges
+type IntradayPriceSource interface {
+	// GetHourlyPriceHistory fetches prices at hourly or finer granularity in a quote currency between from and to
+	GetHourlyPriceHistory(symbol, quote string, from, to time.Time) ([]PricePoint, error)
+}
+
+// FallbackPriceSource tries each price source in order until one returns data
+type FallbackPriceSource struct {
+	sources []PriceSource
+}
+
+// NewFallbackPriceSource creates a price source that falls back through the given sources in order
+func NewFallbackPriceSource(sources ...PriceSource) *FallbackPriceSource {
+	return &FallbackPriceSource{
+		sources: sources,
+	}
+}
+
+// Name returns the names of the underlying sources in fallback order
+func (f *FallbackPriceSource) Name() string {
+	names := make([]string, len(f.sources))
+	for i, source := range f.sources {
+		names[i] = source.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// GetPriceHistoryDays returns the history from the first source that succeeds with data.
+// Every returned point is tagged with the provider that produced it.
+func (f *FallbackPriceSource) GetPriceHistoryDays(symbol string, days int) ([]PricePoint, error) {
+	var errs []error
+	for _, source := range f.sources {
+		points, err := source.GetPriceHistoryDays(symbol, days)
+		if err == nil && len(points) == 0 {
+			err = fmt.Errorf("no price data returned")
+		}
+		if err != nil {
+			fmt.Printf("Warning: price source %s failed for %s: %v\n", source.Name(), symbol, err)
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+
+		for i := range points {
+			if points[i].Source == "" {
+				points[i].Source = source.Name()
+			}
+		}
+		return points, nil
+	}
+
+	return nil, fmt.Errorf("all price sources failed for %s: %w", symbol, errors.Join(errs...))
+}
+
+// GetHourlyPriceHistory returns hourly prices from the first intraday source that succeeds with data
+func (f *FallbackPriceSource) GetHourlyPriceHistory(symbol, quote string, from, to time.Time) ([]PricePoint, error) {
+	var errs []error
+	for _, source := range f.sources {
+		intraday, ok := source.(IntradayPriceSource)
+		if !ok {
+			continue
+		}
+
+		points, err := intraday.GetHourlyPriceHistory(symbol, quote, from, to)
+		if err == nil && len(points) == 0 {
+			err = fmt.Errorf("no price data returned")
+		}
+		if err != nil {
+			fmt.Printf("Warning: price source %s failed for hourly %s: %v\n", source.Name(), symbol, err)
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+			continue
+		}
+		return points, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, ErrHourlyHistoryUnavailable
+	}
+	return nil, fmt.Errorf("all hourly price sources failed for %s: %w", symbol, errors.Join(errs...))
+}
+
+// fetchJSON performs a GET request against a provider API and decodes the JSON response
+func fetchJSON(httpClient *http.Client, provider, url string, target interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s API error (status %d): %s", provider, resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
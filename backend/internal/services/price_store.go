@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+)
+
+// seriesStore persists daily price series in PostgreSQL, keeps them up to date from a fetch function
+// and caches them in Redis. It backs the token price history and the ETH quote currency series.
+type seriesStore struct {
+	series       db.PriceSeries
+	description  string // Names the series in errors and logs, e.g. "price history"
+	fetch        func(key string, days int) ([]PricePoint, error)
+	cacheKey     func(key string) (symbol, quote string)
+	onChange     func(ctx context.Context, key string) // Invalidates data derived from a series, may be nil
+	backfillDays int
+
+	// backfilledSince records how far back each series has been requested from its source,
+	// so ranges older than the source can provide are not refetched on every read
+	backfillMu      sync.Mutex
+	backfilledSince map[string]time.Time
+}
+
+// newSeriesStore creates a series store that backfills PRICE_HISTORY_BACKFILL_DAYS on the first sync
+func newSeriesStore(series db.PriceSeries, description string, fetch func(key string, days int) ([]PricePoint, error), cacheKey func(key string) (string, string)) *seriesStore {
+	backfillDays := 365
+	if backfillDaysStr := os.Getenv("PRICE_HISTORY_BACKFILL_DAYS"); backfillDaysStr != "" {
+		if parsed, err := strconv.Atoi(backfillDaysStr); err == nil && parsed > 0 {
+			backfillDays = parsed
+		}
+	}
+
+	return &seriesStore{
+		series:          series,
+		description:     description,
+		fetch:           fetch,
+		cacheKey:        cacheKey,
+		backfillDays:    backfillDays,
+		backfilledSince: map[string]time.Time{},
+	}
+}
+
+// Sync brings a stored series up to date.
+// The first sync backfills the full history, later syncs only fetch the missing tail.
+func (s *seriesStore) Sync(ctx context.Context, key string) error {
+	latest, found, err := s.series.GetLatestTime(key)
+	if err != nil {
+		return fmt.Errorf("failed to get latest stored %s: %w", s.description, err)
+	}
+
+	days := s.backfillDays
+	var since time.Time
+	if found {
+		// Refetch from the start of the latest stored day so an intraday "current price"
+		// point (as appended by CoinGecko) is replaced by the day's final data
+		since = latest.UTC().Truncate(24 * time.Hour)
+		days = int(time.Since(since).Hours()/24) + 1
+	}
+
+	points, err := s.fetch(key, days)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", s.description, err)
+	}
+
+	if err := s.series.ReplaceSince(key, since, toDBPricePoints(points)); err != nil {
+		return fmt.Errorf("failed to store %s: %w", s.description, err)
+	}
+
+	// Extend older history when the backfill window was raised after the first sync
+	if found {
+		if err := s.Backfill(ctx, key, time.Now().UTC().AddDate(0, 0, -s.backfillDays)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Backfill extends a stored series back to since when older points are missing,
+// replacing its cached copy when new points were stored
+func (s *seriesStore) Backfill(ctx context.Context, key string, since time.Time) error {
+	earliest, found, err := s.series.GetEarliestTime(key)
+	if err != nil {
+		return fmt.Errorf("failed to get earliest stored %s: %w", s.description, err)
+	}
+	// Daily points may start up to a day after since; the first sync covers empty stores
+	if !found || !earliest.After(since.Add(24*time.Hour)) {
+		return nil
+	}
+
+	s.backfillMu.Lock()
+	attempted, exists := s.backfilledSince[key]
+	if exists && !attempted.After(since) {
+		s.backfillMu.Unlock()
+		return nil
+	}
+	s.backfilledSince[key] = since
+	s.backfillMu.Unlock()
+
+	days := int(time.Since(since).Hours()/24) + 1
+	points, err := s.fetch(key, days)
+	if err != nil {
+		return fmt.Errorf("failed to fetch older %s: %w", s.description, err)
+	}
+
+	if err := s.series.InsertBefore(key, earliest, toDBPricePoints(points)); err != nil {
+		return fmt.Errorf("failed to store older %s: %w", s.description, err)
+	}
+
+	data, err := s.Load(key)
+	if err != nil {
+		return err
+	}
+
+	s.setCached(ctx, key, data)
+	if s.onChange != nil {
+		s.onChange(ctx, key)
+	}
+
+	return nil
+}
+
+// toDBPricePoints converts price points from a price source to stored price points
+func toDBPricePoints(points []PricePoint) []db.PricePoint {
+	dbPoints := make([]db.PricePoint, len(points))
+	for i, point := range points {
+		dbPoints[i] = db.PricePoint{
+			RecordedAt: time.UnixMilli(point.Timestamp).UTC(),
+			Price:      point.Price,
+			Source:     point.Source,
+		}
+	}
+	return dbPoints
+}
+
+// Load reads a stored series without contacting its source
+func (s *seriesStore) Load(key string) ([]PricePoint, error) {
+	dbPoints, err := s.series.GetPoints(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from database: %w", s.description, err)
+	}
+
+	points := make([]PricePoint, len(dbPoints))
+	for i, dbPoint := range dbPoints {
+		points[i] = PricePoint{
+			Timestamp: dbPoint.RecordedAt.UnixMilli(),
+			Price:     dbPoint.Price,
+			Source:    dbPoint.Source,
+		}
+	}
+
+	return points, nil
+}
+
+// get returns a series from the cache or the database. When sync is true the store is synced
+// on a cache miss, falling back to stored data if the source is unavailable.
+func (s *seriesStore) get(ctx context.Context, key string, sync bool) ([]PricePoint, error) {
+	// Try to get from cache first
+	symbol, quote := s.cacheKey(key)
+	if cachedData, err := GetCachedPriceHistory(ctx, symbol, quote); err == nil && cachedData != nil {
+		return cachedData, nil
+	}
+
+	if sync {
+		if err := s.Sync(ctx, key); err != nil {
+			fmt.Printf("Warning: failed to sync %s for %s: %v\n", s.description, key, err)
+		}
+	}
+
+	data, err := s.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no %s stored for %s", s.description, key)
+	}
+
+	s.setCached(ctx, key, data)
+	return data, nil
+}
+
+// setCached caches a series, logging failures since the database remains the source of truth
+func (s *seriesStore) setCached(ctx context.Context, key string, data []PricePoint) {
+	symbol, quote := s.cacheKey(key)
+	if err := SetCachedPriceHistory(ctx, symbol, quote, data); err != nil {
+		fmt.Printf("Warning: failed to cache %s for %s: %v\n", s.description, key, err)
+	}
+}
+
+// Refresh syncs a stored series and replaces its cached copy
+func (s *seriesStore) Refresh(ctx context.Context, key string) error {
+	if err := s.Sync(ctx, key); err != nil {
+		return err
+	}
+
+	data, err := s.Load(key)
+	if err != nil {
+		return err
+	}
+
+	symbol, quote := s.cacheKey(key)
+	if err := SetCachedPriceHistory(ctx, symbol, quote, data); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", s.description, err)
+	}
+
+	if s.onChange != nil {
+		s.onChange(ctx, key)
+	}
+
+	return nil
+}
+
+// PriceHistoryStore persists the ETH price history of each token, keyed by symbol
+type PriceHistoryStore struct {
+	*seriesStore
+	source PriceSource
+}
+
+// NewPriceHistoryStore creates a new price history store
+func NewPriceHistoryStore(source PriceSource) *PriceHistoryStore {
+	store := newSeriesStore(db.TokenPrices, "price history", source.GetPriceHistoryDays, func(symbol string) (string, string) {
+		return symbol, QuoteETH
+	})
+	// Converted copies are rebuilt from the changed ETH prices on next use
+	store.onChange = func(ctx context.Context, symbol string) {
+		invalidateConvertedCaches(ctx, "price_history", symbol)
+	}
+
+	return &PriceHistoryStore{seriesStore: store, source: source}
+}
+
+// GetPriceHistory returns the price history for a token, syncing the store on a cache miss
+func (s *PriceHistoryStore) GetPriceHistory(ctx context.Context, symbol string) ([]PricePoint, error) {
+	return s.get(ctx, symbol, true)
+}
+
+// GetStoredPriceHistory returns the stored price history for a token without syncing from the price source
+func (s *PriceHistoryStore) GetStoredPriceHistory(ctx context.Context, symbol string) ([]PricePoint, error) {
+	return s.get(ctx, symbol, false)
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Resampling methods of a yield projection
+const (
+	ProjectionBootstrap  = "bootstrap"   // Draw historical daily returns with replacement
+	ProjectionMonteCarlo = "monte_carlo" // Draw normal daily returns with the historical mean and volatility
+)
+
+const (
+	defaultProjectionSimulations = 5000
+	maxProjectionSimulations     = 50000
+	// maxProjectionSteps caps simulations times horizon days to bound the work of one request
+	maxProjectionSteps = 20000000
+	// projectionBandPoints is the most days the percentile bands are reported at
+	projectionBandPoints = 60
+)
+
+// ErrInvalidProjection is returned when projection parameters fail validation
+var ErrInvalidProjection = errors.New("invalid projection")
+
+// ProjectionInput describes a holding to project and how to resample its history
+type ProjectionInput struct {
+	Allocations []PortfolioAllocation
+	AmountETH   float64 // Starting value for weight allocations; ETH amount allocations set their own
+	HorizonDays int
+	Method      string
+	Simulations int
+	From        time.Time // Range of the historical daily returns resampled
+	To          time.Time
+	Seed        *int64 // Random seed, for reproducible projections
+}
+
+// ProjectionPercentiles holds percentiles of a simulated quantity
+type ProjectionPercentiles struct {
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"` // Median
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+// ProjectionBand holds the percentiles of the simulated value on one day of the horizon
+type ProjectionBand struct {
+	Day   int                   `json:"day"`
+	Value ProjectionPercentiles `json:"value"` // In ETH
+}
+
+// YieldProjection is the distribution of outcomes of holding tokens over a horizon, simulated by resampling
+// the daily ETH returns of the weighted holding. Values and rewards are in ETH; APRs are simple annual rates.
+type YieldProjection struct {
+	Method            string                `json:"method"`
+	Simulations       int                   `json:"simulations"`
+	Seed              int64                 `json:"seed"`
+	HorizonDays       int                   `json:"horizon_days"`
+	AmountETH         float64               `json:"amount_eth"`
+	Weights           map[string]float64    `json:"weights"`
+	HistoryFrom       time.Time             `json:"history_from"`
+	HistoryTo         time.Time             `json:"history_to"`
+	Observations      int                   `json:"observations"` // Historical daily returns resampled
+	HistoricalAPR     float64               `json:"historical_apr"`
+	HistoricalVol     float64               `json:"historical_volatility"` // Annualized
+	FinalValue        ProjectionPercentiles `json:"final_value"`
+	Rewards           ProjectionPercentiles `json:"rewards"`
+	APR               ProjectionPercentiles `json:"apr"`
+	ProbabilityOfLoss float64               `json:"probability_of_loss"`
+	Bands             []ProjectionBand      `json:"bands"`
+}
+
+// ProjectYield simulates the value of a holding over a horizon by bootstrap or Monte Carlo resampling of the
+// historical daily returns of its tokens, weighted and rebalanced daily
+func (s *ValuationService) ProjectYield(ctx context.Context, input ProjectionInput) (*YieldProjection, error) {
+	symbols, weights, total, byAmount, err := normalizeAllocations(input.Allocations)
+	if err != nil {
+		return nil, err
+	}
+	amount := input.AmountETH
+	if byAmount {
+		amount = total
+	}
+	if err := input.validate(amount); err != nil {
+		return nil, err
+	}
+
+	method := input.Method
+	if method == "" {
+		method = ProjectionBootstrap
+	}
+	simulations := input.Simulations
+	if simulations == 0 {
+		simulations = defaultProjectionSimulations
+	}
+	if simulations*input.HorizonDays > maxProjectionSteps {
+		return nil, fmt.Errorf("%w: simulations times horizon days must not exceed %d", ErrInvalidProjection, maxProjectionSteps)
+	}
+	seed := time.Now().UnixNano()
+	if input.Seed != nil {
+		seed = *input.Seed
+	}
+
+	returns := make([]map[int64]float64, len(symbols))
+	for i, symbol := range symbols {
+		priceHistory, err := s.GetTokenHistory(ctx, symbol, HistoryQuery{From: input.From, To: input.To, Interval: IntervalDaily, Quote: QuoteETH})
+		if err != nil {
+			return nil, err
+		}
+		returns[i] = dailyReturnsByDay(priceHistory)
+	}
+	history := blendedDailyReturns(returns, weights)
+	if len(history) < 2 {
+		return nil, fmt.Errorf("%w: the tokens share fewer than two daily returns", ErrInsufficientPriceData)
+	}
+	mean, stdDev := meanStdDev(history)
+
+	projection := &YieldProjection{
+		Method:        method,
+		Simulations:   simulations,
+		Seed:          seed,
+		HorizonDays:   input.HorizonDays,
+		AmountETH:     amount,
+		Weights:       map[string]float64{},
+		HistoryFrom:   input.From,
+		HistoryTo:     input.To,
+		Observations:  len(history),
+		HistoricalAPR: mean * periodsPerYear,
+		HistoricalVol: stdDev * math.Sqrt(periodsPerYear),
+	}
+	for i, symbol := range symbols {
+		projection.Weights[symbol] = weights[i]
+	}
+
+	bandDays := projectionBandDays(input.HorizonDays)
+	checkpoints := make([]int, input.HorizonDays+1) // Band index per day, -1 when not reported
+	for day := range checkpoints {
+		checkpoints[day] = -1
+	}
+	for i, day := range bandDays {
+		checkpoints[day] = i
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	draw := func() float64 { return history[random.Intn(len(history))] }
+	if method == ProjectionMonteCarlo {
+		draw = func() float64 { return mean + stdDev*random.NormFloat64() }
+	}
+
+	bandValues := make([][]float64, len(bandDays))
+	for i := range bandValues {
+		bandValues[i] = make([]float64, simulations)
+	}
+	finalValues := make([]float64, simulations)
+	losses := 0
+	for sim := 0; sim < simulations; sim++ {
+		value := amount
+		for day := 1; day <= input.HorizonDays; day++ {
+			value *= 1 + draw()
+			if index := checkpoints[day]; index >= 0 {
+				bandValues[index][sim] = value
+			}
+		}
+		finalValues[sim] = value
+		if value < amount {
+			losses++
+		}
+	}
+
+	projection.FinalValue = percentilesOf(finalValues)
+	projection.ProbabilityOfLoss = float64(losses) / float64(simulations)
+
+	// Rewards and APR are monotonic in the final value, so their percentiles follow from it
+	final := projection.FinalValue
+	projection.Rewards = ProjectionPercentiles{
+		P5: final.P5 - amount, P25: final.P25 - amount, P50: final.P50 - amount, P75: final.P75 - amount, P95: final.P95 - amount,
+	}
+	annualize := func(value float64) float64 {
+		return (value/amount - 1) * periodsPerYear / float64(input.HorizonDays)
+	}
+	projection.APR = ProjectionPercentiles{
+		P5: annualize(final.P5), P25: annualize(final.P25), P50: annualize(final.P50), P75: annualize(final.P75), P95: annualize(final.P95),
+	}
+
+	for i, day := range bandDays {
+		projection.Bands = append(projection.Bands, ProjectionBand{Day: day, Value: percentilesOf(bandValues[i])})
+	}
+
+	return projection, nil
+}
+
+// validate checks the amount, horizon, method and simulation count of a projection
+func (input ProjectionInput) validate(amount float64) error {
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return fmt.Errorf("%w: amount_eth must be positive", ErrInvalidProjection)
+	}
+	if input.HorizonDays < 1 || input.HorizonDays > MaxHistoryDays {
+		return fmt.Errorf("%w: horizon must be between 1 and %d days", ErrInvalidProjection, MaxHistoryDays)
+	}
+	if input.Method != "" && input.Method != ProjectionBootstrap && input.Method != ProjectionMonteCarlo {
+		return fmt.Errorf("%w: unsupported method %s (supported: bootstrap, monte_carlo)", ErrInvalidProjection, input.Method)
+	}
+	if input.Simulations < 0 || input.Simulations > maxProjectionSimulations {
+		return fmt.Errorf("%w: simulations must be between 1 and %d", ErrInvalidProjection, maxProjectionSimulations)
+	}
+	if !input.From.Before(input.To) {
+		return fmt.Errorf("%w: the history range is empty", ErrInvalidProjection)
+	}
+	return nil
+}
+
+// projectionBandDays returns the days of a horizon the value distribution is reported at, oldest first and
+// always including the last day
+func projectionBandDays(horizonDays int) []int {
+	var days []int
+	for k := 1; k <= projectionBandPoints && k <= horizonDays; k++ {
+		day := int(math.Round(float64(k) * float64(horizonDays) / math.Min(projectionBandPoints, float64(horizonDays))))
+		if len(days) == 0 || days[len(days)-1] != day {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// blendedDailyReturns returns the weighted daily return of tokens on the days every token has one, oldest first
+func blendedDailyReturns(returns []map[int64]float64, weights []float64) []float64 {
+	days := sharedDays(returns)
+	blended := make([]float64, len(days))
+	for d, day := range days {
+		for i, tokenReturns := range returns {
+			blended[d] += weights[i] * tokenReturns[day]
+		}
+	}
+	return blended
+}
+
+// percentilesOf returns the 5th, 25th, 50th, 75th and 95th percentiles of values, interpolating between ranks.
+// values is sorted in place. Empty values have zero percentiles.
+func percentilesOf(values []float64) ProjectionPercentiles {
+	if len(values) == 0 {
+		return ProjectionPercentiles{}
+	}
+
+	sort.Float64s(values)
+	percentile := func(p float64) float64 {
+		position := p * float64(len(values)-1)
+		lower := int(math.Floor(position))
+		if lower+1 >= len(values) {
+			return values[len(values)-1]
+		}
+		return values[lower] + (position-float64(lower))*(values[lower+1]-values[lower])
+	}
+
+	return ProjectionPercentiles{
+		P5:  percentile(0.05),
+		P25: percentile(0.25),
+		P50: percentile(0.50),
+		P75: percentile(0.75),
+		P95: percentile(0.95),
+	}
+}
+
+// sharedDays returns the days every token has a return for, oldest first
+func sharedDays(returns []map[int64]float64) []int64 {
+	if len(returns) == 0 {
+		return nil
+	}
+
+	var days []int64
+	for day := range returns[0] {
+		shared := true
+		for _, tokenReturns := range returns[1:] {
+			if _, exists := tokenReturns[day]; !exists {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	return days
+}
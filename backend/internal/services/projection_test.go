@@ -0,0 +1,105 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPercentilesOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   ProjectionPercentiles
+	}{
+		{name: "empty", values: []float64{}, want: ProjectionPercentiles{}},
+		{name: "one value", values: []float64{1.2}, want: ProjectionPercentiles{P5: 1.2, P25: 1.2, P50: 1.2, P75: 1.2, P95: 1.2}},
+		{name: "two values interpolate", values: []float64{2, 1}, want: ProjectionPercentiles{P5: 1.05, P25: 1.25, P50: 1.5, P75: 1.75, P95: 1.95}},
+		{name: "all equal", values: []float64{3, 3, 3, 3}, want: ProjectionPercentiles{P5: 3, P25: 3, P50: 3, P75: 3, P95: 3}},
+		{
+			// Positions 0.2, 1, 2, 3 and 3.8 of the sorted values 0..4
+			name:   "unsorted",
+			values: []float64{4, 0, 3, 1, 2},
+			want:   ProjectionPercentiles{P5: 0.2, P25: 1, P50: 2, P75: 3, P95: 3.8},
+		},
+		{name: "ties", values: []float64{1, 2, 2, 2, 5}, want: ProjectionPercentiles{P5: 1.2, P25: 2, P50: 2, P75: 2, P95: 4.4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := percentilesOf(tt.values)
+			if !approxEqual(got.P5, tt.want.P5) || !approxEqual(got.P25, tt.want.P25) || !approxEqual(got.P50, tt.want.P50) ||
+				!approxEqual(got.P75, tt.want.P75) || !approxEqual(got.P95, tt.want.P95) {
+				t.Errorf("percentilesOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectionBandDays(t *testing.T) {
+	tests := []struct {
+		name        string
+		horizonDays int
+		wantCount   int
+		wantFirst   int
+	}{
+		{name: "one day", horizonDays: 1, wantCount: 1, wantFirst: 1},
+		{name: "every day below the band points", horizonDays: 30, wantCount: 30, wantFirst: 1},
+		{name: "every day at the band points", horizonDays: projectionBandPoints, wantCount: projectionBandPoints, wantFirst: 1},
+		{name: "sampled over a year", horizonDays: 365, wantCount: projectionBandPoints, wantFirst: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := projectionBandDays(tt.horizonDays)
+			if len(days) != tt.wantCount || days[0] != tt.wantFirst || days[len(days)-1] != tt.horizonDays {
+				t.Fatalf("projectionBandDays(%d) = %v, want %d days from %d to %d", tt.horizonDays, days, tt.wantCount, tt.wantFirst, tt.horizonDays)
+			}
+			for i := 1; i < len(days); i++ {
+				if days[i] <= days[i-1] {
+					t.Errorf("projectionBandDays(%d) = %v, want strictly increasing days", tt.horizonDays, days)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBlendedDailyReturns(t *testing.T) {
+	tests := []struct {
+		name    string
+		returns []map[int64]float64
+		weights []float64
+		want    []float64
+	}{
+		{name: "no tokens", returns: nil, weights: nil, want: []float64{}},
+		{name: "one token", returns: []map[int64]float64{{testDayMs: 0.02, 0: 0.01}}, weights: []float64{1}, want: []float64{0.01, 0.02}},
+		{
+			name:    "only shared days",
+			returns: []map[int64]float64{{0: 0.01, testDayMs: 0.02}, {testDayMs: 0.04, 2 * testDayMs: 0.05}},
+			weights: []float64{0.5, 0.5},
+			want:    []float64{0.03},
+		},
+		{name: "no shared days", returns: []map[int64]float64{{0: 0.01}, {testDayMs: 0.02}}, weights: []float64{0.5, 0.5}, want: []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blendedDailyReturns(tt.returns, tt.weights); !weightsNear(got, tt.want, 1e-12) {
+				t.Errorf("blendedDailyReturns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharedDays(t *testing.T) {
+	returns := []map[int64]float64{
+		{2 * testDayMs: 0, 0: 0, testDayMs: 0},
+		{testDayMs: 0, 2 * testDayMs: 0, 3 * testDayMs: 0},
+	}
+	if got, want := sharedDays(returns), []int64{testDayMs, 2 * testDayMs}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sharedDays() = %v, want %v", got, want)
+	}
+	if got := sharedDays(nil); got != nil {
+		t.Errorf("sharedDays(nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+)
+
+// Quote currencies prices can be reported in. Token prices are stored in ETH and converted to
+// the other quotes through the stored ETH price series of each currency.
+const (
+	QuoteETH = "eth"
+	QuoteUSD = "usd"
+	QuoteEUR = "eur"
+	QuoteBTC = "btc"
+)
+
+// Quotes lists every supported quote currency
+var Quotes = []string{QuoteETH, QuoteUSD, QuoteEUR, QuoteBTC}
+
+// ErrUnsupportedQuote is returned for quote currencies that are not supported
+var ErrUnsupportedQuote = errors.New("unsupported quote currency")
+
+// maxQuoteGap is how far back the ETH price used to convert a point may lie before the point is dropped
+const maxQuoteGap = 48 * time.Hour
+
+// ParseQuote validates a quote currency, returning ETH when none is given
+func ParseQuote(quote string) (string, error) {
+	if quote == "" {
+		return QuoteETH, nil
+	}
+
+	quote = strings.ToLower(quote)
+	for _, supported := range Quotes {
+		if quote == supported {
+			return quote, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w %q (expected %s)", ErrUnsupportedQuote, quote, strings.Join(Quotes, ", "))
+}
+
+// quoteCacheKey returns the cache key of data for a symbol in a quote currency.
+// ETH data keeps the unsuffixed key so existing keys and invalidation stay valid.
+func quoteCacheKey(prefix, symbol, quote string) string {
+	if quote == "" || quote == QuoteETH {
+		return fmt.Sprintf("%s:%s", prefix, symbol)
+	}
+	return fmt.Sprintf("%s:%s:%s", prefix, symbol, quote)
+}
+
+// QuoteCacheKeys returns the cache keys of data for a symbol in every quote currency
+func QuoteCacheKeys(prefix, symbol string) []string {
+	keys := make([]string, len(Quotes))
+	for i, quote := range Quotes {
+		keys[i] = quoteCacheKey(prefix, symbol, quote)
+	}
+	return keys
+}
+
+// invalidateConvertedCaches removes the cached non-ETH copies of data for a symbol after the ETH data changed
+func invalidateConvertedCaches(ctx context.Context, prefix, symbol string) {
+	for _, quote := range Quotes[1:] {
+		key := quoteCacheKey(prefix, symbol, quote)
+		if err := cache.Delete(ctx, key); err != nil {
+			fmt.Printf("Warning: failed to invalidate cache key %s: %v\n", key, err)
+		}
+	}
+}
+
+// GetETHPriceHistoryDays fetches the daily price of ETH in the given currency over the last given number of days
+func (c *CoinGeckoClient) GetETHPriceHistoryDays(currency string, days int) ([]PricePoint, error) {
+	url := fmt.Sprintf("%s/coins/ethereum/market_chart?vs_currency=%s&days=%d&interval=daily", c.baseURL, currency, days)
+
+	var history PriceHistory
+	if err := c.getJSON(url, &history); err != nil {
+		return nil, err
+	}
+
+	var pricePoints []PricePoint
+	for _, price := range history.Prices {
+		if len(price) >= 2 {
+			timestamp, ok1 := price[0].(float64)
+			priceValue, ok2 := price[1].(float64)
+			if ok1 && ok2 {
+				pricePoints = append(pricePoints, PricePoint{
+					Timestamp: int64(timestamp),
+					Price:     priceValue,
+					Source:    c.Name(),
+				})
+			}
+		}
+	}
+
+	return pricePoints, nil
+}
+
+// ETHQuoteStore persists the daily ETH price in each quote currency, keyed by currency
+type ETHQuoteStore struct {
+	*seriesStore
+}
+
+// NewETHQuoteStore creates a new ETH quote store
+func NewETHQuoteStore(client *CoinGeckoClient) *ETHQuoteStore {
+	store := newSeriesStore(db.ETHQuotePrices, "ETH price history", client.GetETHPriceHistoryDays, func(currency string) (string, string) {
+		return "ETH", currency
+	})
+	return &ETHQuoteStore{seriesStore: store}
+}
+
+// GetQuoteHistory returns the ETH price series of a currency. When sync is true the store is synced
+// on a cache miss; otherwise only stored data is served.
+func (s *ETHQuoteStore) GetQuoteHistory(ctx context.Context, currency string, sync bool) ([]PricePoint, error) {
+	return s.get(ctx, currency, sync)
+}
+
+// convertPriceHistory converts ETH-denominated prices to a quote currency using the ETH price series
+// of that currency. Each point uses the latest ETH price at or before it; points without a recent
+// ETH price are dropped.
+func convertPriceHistory(points []PricePoint, ethQuotes []PricePoint) []PricePoint {
+	sortedQuotes := append([]PricePoint{}, ethQuotes...)
+	sort.Slice(sortedQuotes, func(i, j int) bool {
+		return sortedQuotes[i].Timestamp < sortedQuotes[j].Timestamp
+	})
+
+	converted := make([]PricePoint, 0, len(points))
+	for _, point := range points {
+		// Index of the first ETH price after the point
+		i := sort.Search(len(sortedQuotes), func(i int) bool {
+			return sortedQuotes[i].Timestamp > point.Timestamp
+		})
+		if i == 0 {
+			continue
+		}
+
+		ethQuote := sortedQuotes[i-1]
+		if point.Timestamp-ethQuote.Timestamp > maxQuoteGap.Milliseconds() {
+			continue
+		}
+
+		converted = append(converted, PricePoint{
+			Timestamp: point.Timestamp,
+			Price:     point.Price * ethQuote.Price,
+			Source:    point.Source,
+		})
+	}
+
+	return converted
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// periodsPerYear annualizes daily metrics; tokens trade every day of the year
+	periodsPerYear = 365
+	// riskConfidence is the confidence level of the historical VaR and CVaR
+	riskConfidence = 0.95
+)
+
+// ErrInsufficientPriceData is returned when a range holds too few prices to compute a metric
+var ErrInsufficientPriceData = errors.New("insufficient price data")
+
+// RiskMetrics holds the risk profile of a token computed from its daily price history.
+// Returns, volatility and deviations are fractions (0.05 = 5%); VaR and CVaR are daily losses
+// reported as positive fractions.
+type RiskMetrics struct {
+	TokenSymbol  string    `json:"token_symbol"`
+	Quote        string    `json:"quote"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Observations int       `json:"observations"` // Number of daily returns
+	BaseRate     float64   `json:"base_rate"`    // Annual rate excess returns are measured against
+
+	AnnualizedReturn     float64 `json:"annualized_return"`
+	AnnualizedVolatility float64 `json:"annualized_volatility"`
+	DownsideDeviation    float64 `json:"downside_deviation"` // Annualized, below the daily base rate
+	SharpeRatio          float64 `json:"sharpe_ratio"`
+	SortinoRatio         float64 `json:"sortino_ratio"`
+
+	MaxDrawdown          float64 `json:"max_drawdown"`
+	MaxDrawdownPeak      int64   `json:"max_drawdown_peak"`   // Timestamp in milliseconds
+	MaxDrawdownTrough    int64   `json:"max_drawdown_trough"` // Timestamp in milliseconds
+	MaxDrawdownDays      float64 `json:"max_drawdown_days"`   // From the peak until recovery or the end of the range
+	MaxDrawdownRecovered bool    `json:"max_drawdown_recovered"`
+
+	VaR95  float64 `json:"var_95"`
+	CVaR95 float64 `json:"cvar_95"`
+
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// BaseStakingRate returns the annual base staking rate risk-adjusted ratios are measured against,
+// configured with BASE_STAKING_RATE
+func BaseStakingRate() float64 {
+	baseRate := 0.03
+	if baseRateStr := os.Getenv("BASE_STAKING_RATE"); baseRateStr != "" {
+		if parsed, err := strconv.ParseFloat(baseRateStr, 64); err == nil {
+			baseRate = parsed
+		}
+	}
+	return baseRate
+}
+
+// GetTokenRisk computes the risk metrics of a token over the range and quote currency of a daily history query
+func (s *ValuationService) GetTokenRisk(ctx context.Context, symbol string, query HistoryQuery, baseRate float64) (*RiskMetrics, error) {
+	query.Interval = IntervalDaily
+	priceHistory, err := s.GetTokenHistory(ctx, symbol, query)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := CalculateRiskMetrics(priceHistory, baseRate)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s", err, symbol)
+	}
+
+	metrics.TokenSymbol = symbol
+	metrics.Quote = query.Quote
+	metrics.From = query.From
+	metrics.To = query.To
+	return metrics, nil
+}
+
+// CalculateRiskMetrics computes volatility, drawdown, risk-adjusted ratios and historical VaR/CVaR
+// from daily prices
+func CalculateRiskMetrics(priceHistory []PricePoint, baseRate float64) (*RiskMetrics, error) {
+	points := sortedByTime(priceHistory)
+	returns := dailyReturns(points)
+	if len(returns) < 2 {
+		return nil, ErrInsufficientPriceData
+	}
+
+	meanReturn, stdDev := meanStdDev(returns)
+
+	// Downside deviation only counts returns below the daily base rate
+	target := baseRate / periodsPerYear
+	downsideSquares := 0.0
+	for _, r := range returns {
+		if r < target {
+			downsideSquares += (r - target) * (r - target)
+		}
+	}
+	downsideDeviation := math.Sqrt(downsideSquares/float64(len(returns))) * math.Sqrt(periodsPerYear)
+
+	metrics := &RiskMetrics{
+		Observations:         len(returns),
+		BaseRate:             baseRate,
+		AnnualizedReturn:     meanReturn * periodsPerYear,
+		AnnualizedVolatility: stdDev * math.Sqrt(periodsPerYear),
+		DownsideDeviation:    downsideDeviation,
+		LastUpdated:          time.Now(),
+	}
+
+	excessReturn := metrics.AnnualizedReturn - baseRate
+	if metrics.AnnualizedVolatility > 0 {
+		metrics.SharpeRatio = excessReturn / metrics.AnnualizedVolatility
+	}
+	if downsideDeviation > 0 {
+		metrics.SortinoRatio = excessReturn / downsideDeviation
+	}
+
+	applyMaxDrawdown(metrics, points)
+	metrics.VaR95, metrics.CVaR95 = historicalVaR(returns, riskConfidence)
+
+	return metrics, nil
+}
+
+// applyMaxDrawdown sets the largest peak-to-trough decline of points sorted oldest first and how long
+// the price stayed below the peak
+func applyMaxDrawdown(metrics *RiskMetrics, points []PricePoint) {
+	peak := points[0]
+	for i, point := range points {
+		if point.Price > peak.Price {
+			peak = point
+		}
+		if peak.Price <= 0 {
+			continue
+		}
+
+		drawdown := 1 - point.Price/peak.Price
+		if drawdown <= metrics.MaxDrawdown {
+			continue
+		}
+
+		metrics.MaxDrawdown = drawdown
+		metrics.MaxDrawdownPeak = peak.Timestamp
+		metrics.MaxDrawdownTrough = point.Timestamp
+
+		// The drawdown lasts until the price first regains the peak
+		end := points[len(points)-1].Timestamp
+		metrics.MaxDrawdownRecovered = false
+		for _, later := range points[i+1:] {
+			if later.Price >= peak.Price {
+				end = later.Timestamp
+				metrics.MaxDrawdownRecovered = true
+				break
+			}
+		}
+		metrics.MaxDrawdownDays = float64(end-peak.Timestamp) / float64(24*time.Hour.Milliseconds())
+	}
+}
+
+// historicalVaR returns the daily loss not exceeded at the confidence level and the average loss
+// beyond it, both as positive fractions
+func historicalVaR(returns []float64, confidence float64) (float64, float64) {
+	if len(returns) == 0 {
+		return 0, 0
+	}
+
+	sorted := append([]float64{}, returns...)
+	sort.Float64s(sorted)
+
+	// Number of returns in the tail, at least one
+	tail := int(math.Floor(float64(len(sorted)) * (1 - confidence)))
+	if tail < 1 {
+		tail = 1
+	}
+
+	sum := 0.0
+	for _, r := range sorted[:tail] {
+		sum += r
+	}
+
+	return -sorted[tail-1], -sum / float64(tail)
+}
+
+// dailyReturns returns the simple returns between consecutive points sorted oldest first
+func dailyReturns(points []PricePoint) []float64 {
+	returns := make([]float64, 0, len(points))
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Price > 0 {
+			returns = append(returns, points[i].Price/points[i-1].Price-1)
+		}
+	}
+	return returns
+}
+
+// meanStdDev returns the mean and population standard deviation of values
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	sumSquares := 0.0
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+
+	return mean, math.Sqrt(sumSquares / float64(len(values)))
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// approxEqual reports whether two floats agree to well below the precision of the metrics
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestHistoricalVaR(t *testing.T) {
+	// 100 returns of -0.10, -0.09, ..., 0.89 put the five worst in the 95% tail
+	hundred := make([]float64, 100)
+	for i := range hundred {
+		hundred[i] = float64(i-10) / 100
+	}
+
+	tests := []struct {
+		name     string
+		returns  []float64
+		wantVaR  float64
+		wantCVaR float64
+	}{
+		{name: "empty", returns: nil, wantVaR: 0, wantCVaR: 0},
+		{name: "one return", returns: []float64{-0.02}, wantVaR: 0.02, wantCVaR: 0.02},
+		{name: "tail below one return uses the worst", returns: []float64{0.01, -0.03, 0.02, -0.01}, wantVaR: 0.03, wantCVaR: 0.03},
+		{name: "five return tail", returns: hundred, wantVaR: 0.06, wantCVaR: 0.08},
+		{name: "all equal returns", returns: []float64{0.001, 0.001, 0.001}, wantVaR: -0.001, wantCVaR: -0.001},
+		{name: "ties in the tail", returns: []float64{-0.05, -0.05, 0.01, 0.02}, wantVaR: 0.05, wantCVaR: 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotVaR, gotCVaR := historicalVaR(tt.returns, riskConfidence)
+			if !approxEqual(gotVaR, tt.wantVaR) || !approxEqual(gotCVaR, tt.wantCVaR) {
+				t.Errorf("historicalVaR() = (%v, %v), want (%v, %v)", gotVaR, gotCVaR, tt.wantVaR, tt.wantCVaR)
+			}
+		})
+	}
+}
+
+func TestHistoricalVaRKeepsInputOrder(t *testing.T) {
+	returns := []float64{0.02, -0.01, 0.03}
+	historicalVaR(returns, riskConfidence)
+
+	if returns[0] != 0.02 || returns[1] != -0.01 || returns[2] != 0.03 {
+		t.Errorf("historicalVaR() reordered its input: %v", returns)
+	}
+}
+
+func TestCalculateRiskMetricsInsufficientData(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []PricePoint
+	}{
+		{name: "empty", points: nil},
+		{name: "one point", points: []PricePoint{{Timestamp: 0, Price: 1}}},
+		{name: "one return", points: []PricePoint{{Timestamp: 0, Price: 1}, {Timestamp: testDayMs, Price: 1.01}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CalculateRiskMetrics(tt.points, 0); !errors.Is(err, ErrInsufficientPriceData) {
+				t.Errorf("CalculateRiskMetrics() error = %v, want %v", err, ErrInsufficientPriceData)
+			}
+		})
+	}
+}
+
+func TestCalculateRiskMetricsAllEqualReturns(t *testing.T) {
+	// Constant daily growth has no volatility, so the ratios stay zero rather than dividing by zero
+	points := make([]PricePoint, 10)
+	price := 1.0
+	for i := range points {
+		points[i] = PricePoint{Timestamp: int64(i) * testDayMs, Price: price}
+		price *= 1.001
+	}
+
+	metrics, err := CalculateRiskMetrics(points, 0.03)
+	if err != nil {
+		t.Fatalf("CalculateRiskMetrics() error = %v", err)
+	}
+
+	if metrics.Observations != 9 {
+		t.Errorf("Observations = %d, want 9", metrics.Observations)
+	}
+	if !approxEqual(metrics.AnnualizedReturn, 0.001*periodsPerYear) {
+		t.Errorf("AnnualizedReturn = %v, want %v", metrics.AnnualizedReturn, 0.001*periodsPerYear)
+	}
+	if metrics.AnnualizedVolatility > 1e-9 || metrics.SharpeRatio != 0 || metrics.SortinoRatio != 0 {
+		t.Errorf("volatility and ratios = (%v, %v, %v), want zero", metrics.AnnualizedVolatility, metrics.SharpeRatio, metrics.SortinoRatio)
+	}
+	if metrics.MaxDrawdown != 0 {
+		t.Errorf("MaxDrawdown = %v, want 0", metrics.MaxDrawdown)
+	}
+	if !approxEqual(metrics.VaR95, -0.001) || !approxEqual(metrics.CVaR95, -0.001) {
+		t.Errorf("VaR95, CVaR95 = (%v, %v), want (-0.001, -0.001)", metrics.VaR95, metrics.CVaR95)
+	}
+}
+
+func TestCalculateRiskMetricsDrawdown(t *testing.T) {
+	points := []PricePoint{
+		{Timestamp: 0, Price: 1},
+		{Timestamp: testDayMs, Price: 1.2},
+		{Timestamp: 2 * testDayMs, Price: 0.9},
+		{Timestamp: 3 * testDayMs, Price: 1.0},
+		{Timestamp: 4 * testDayMs, Price: 1.3},
+	}
+
+	metrics, err := CalculateRiskMetrics(points, 0)
+	if err != nil {
+		t.Fatalf("CalculateRiskMetrics() error = %v", err)
+	}
+
+	if !approxEqual(metrics.MaxDrawdown, 0.25) {
+		t.Errorf("MaxDrawdown = %v, want 0.25", metrics.MaxDrawdown)
+	}
+	if metrics.MaxDrawdownPeak != testDayMs || metrics.MaxDrawdownTrough != 2*testDayMs {
+		t.Errorf("drawdown peak, trough = (%d, %d), want (%d, %d)", metrics.MaxDrawdownPeak, metrics.MaxDrawdownTrough, testDayMs, 2*testDayMs)
+	}
+	if !metrics.MaxDrawdownRecovered || metrics.MaxDrawdownDays != 3 {
+		t.Errorf("recovered, days = (%v, %v), want (true, 3)", metrics.MaxDrawdownRecovered, metrics.MaxDrawdownDays)
+	}
+}
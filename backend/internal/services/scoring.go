@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+)
+
+// Composite score components. APR, stability, TVL and liquidity are normalized across the scored tokens
+// (min-max, TVL and liquidity on a log scale); valuation and depeg are scored on absolute scales.
+const (
+	ScoreComponentAPR       = "apr"
+	ScoreComponentStability = "stability"
+	ScoreComponentTVL       = "tvl"
+	ScoreComponentValuation = "valuation"
+	ScoreComponentDepeg     = "depeg"
+	ScoreComponentLiquidity = "liquidity"
+)
+
+// ScoreComponents lists every composite score component
+var ScoreComponents = []string{
+	ScoreComponentAPR,
+	ScoreComponentStability,
+	ScoreComponentTVL,
+	ScoreComponentValuation,
+	ScoreComponentDepeg,
+	ScoreComponentLiquidity,
+}
+
+// depegToleranceBps is the premium or discount to NAV at which the depeg component reaches zero
+const depegToleranceBps = 200
+
+// remarkScores maps valuation remarks to the valuation component; undervalued tokens score higher
+var remarkScores = map[string]float64{
+	"Very Undervalued": 1,
+	"Undervalued":      0.75,
+	"Fair Value":       0.5,
+	"Overvalued":       0.25,
+	"Very Overvalued":  0,
+}
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+var (
+	// ErrScoringProfileNotFound is returned when no scoring profile matches the given name
+	ErrScoringProfileNotFound = db.ErrScoringProfileNotFound
+	// ErrInvalidScoringProfile is returned when a scoring profile fails validation
+	ErrInvalidScoringProfile = errors.New("invalid scoring profile")
+)
+
+// ScoringProfile is a named set of composite score weights
+type ScoringProfile struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Weights     map[string]float64 `json:"weights"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// ScoringProfileInput is the request body for creating or replacing a scoring profile
+type ScoringProfileInput struct {
+	Description string             `json:"description" example:"Favors stable, large, liquid tokens"`
+	Weights     map[string]float64 `json:"weights"`
+}
+
+// CompositeScore is a 0-100 score of a token under a scoring profile with the contribution of each component
+type CompositeScore struct {
+	Profile    string           `json:"profile"`
+	Score      float64          `json:"score"`
+	Components []ScoreComponent `json:"components"`
+}
+
+// ScoreComponent explains one component of a composite score. Components without input data are skipped
+// and the weights of the others are scaled up to keep the score on a 0-100 scale.
+type ScoreComponent struct {
+	Name         string   `json:"name"`
+	Weight       float64  `json:"weight"`       // Weight in the profile
+	Value        *float64 `json:"value"`        // Raw input, nil when unavailable
+	Normalized   *float64 `json:"normalized"`   // Input scaled to 0-1, higher is better
+	Contribution float64  `json:"contribution"` // Points added to the score
+}
+
+// ScoringService handles scoring profiles and composite token scores
+type ScoringService struct{}
+
+// NewScoringService creates a new scoring service
+func NewScoringService() *ScoringService {
+	return &ScoringService{}
+}
+
+// GetProfiles retrieves all scoring profiles
+func (s *ScoringService) GetProfiles(ctx context.Context) ([]ScoringProfile, error) {
+	dbProfiles, err := db.GetScoringProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scoring profiles from database: %w", err)
+	}
+
+	profiles := make([]ScoringProfile, len(dbProfiles))
+	for i, dbProfile := range dbProfiles {
+		profiles[i] = newScoringProfileFromDB(dbProfile)
+	}
+
+	return profiles, nil
+}
+
+// GetProfile retrieves a scoring profile by name
+func (s *ScoringService) GetProfile(ctx context.Context, name string) (*ScoringProfile, error) {
+	dbProfile, err := db.GetScoringProfile(name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrScoringProfileNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scoring profile from database: %w", err)
+	}
+
+	profile := newScoringProfileFromDB(*dbProfile)
+	return &profile, nil
+}
+
+// SaveProfile validates and creates or replaces a scoring profile
+func (s *ScoringService) SaveProfile(ctx context.Context, name string, input ScoringProfileInput) (*ScoringProfile, error) {
+	if !profileNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be 1-50 lowercase letters, digits or dashes", ErrInvalidScoringProfile)
+	}
+
+	total := 0.0
+	for component, weight := range input.Weights {
+		if !isScoreComponent(component) {
+			return nil, fmt.Errorf("%w: unknown component %s (supported: %s)", ErrInvalidScoringProfile, component, strings.Join(ScoreComponents, ", "))
+		}
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("%w: weight of %s must be a non-negative number", ErrInvalidScoringProfile, component)
+		}
+		total += weight
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("%w: at least one weight must be positive", ErrInvalidScoringProfile)
+	}
+
+	dbProfile, err := db.UpsertScoringProfile(db.ScoringProfile{
+		Name:        name,
+		Description: input.Description,
+		Weights:     input.Weights,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store scoring profile: %w", err)
+	}
+
+	profile := newScoringProfileFromDB(*dbProfile)
+	return &profile, nil
+}
+
+// DeleteProfile removes a scoring profile
+func (s *ScoringService) DeleteProfile(ctx context.Context, name string) error {
+	return db.DeleteScoringProfile(name)
+}
+
+// ScoreValuations sets the composite score of each valuation under a profile and returns them sorted by
+// score, highest first. Components normalized across tokens are relative to the given valuations.
+func (s *ScoringService) ScoreValuations(ctx context.Context, valuations []ValuationData, profile *ScoringProfile) []ValuationData {
+	inputs := make([]map[string]*float64, len(valuations))
+	for i, valuation := range valuations {
+		inputs[i] = scoreInputs(valuation)
+	}
+
+	// Min-max bounds of the components normalized across tokens
+	type bounds struct{ min, max float64 }
+	relative := map[string]*bounds{}
+	for _, component := range []string{ScoreComponentAPR, ScoreComponentStability, ScoreComponentTVL, ScoreComponentLiquidity} {
+		for _, input := range inputs {
+			value := input[component]
+			if value == nil {
+				continue
+			}
+			b := relative[component]
+			if b == nil {
+				relative[component] = &bounds{*value, *value}
+				continue
+			}
+			b.min = math.Min(b.min, *value)
+			b.max = math.Max(b.max, *value)
+		}
+	}
+
+	scored := make([]ValuationData, len(valuations))
+	for i, valuation := range valuations {
+		normalized := map[string]*float64{}
+		for component, value := range inputs[i] {
+			if value == nil {
+				continue
+			}
+
+			var score float64
+			switch component {
+			case ScoreComponentValuation:
+				score = *value
+			case ScoreComponentDepeg:
+				score = math.Max(0, 1-math.Abs(*value)/depegToleranceBps)
+			case ScoreComponentTVL, ScoreComponentLiquidity:
+				b := relative[component]
+				score = minMax(math.Log1p(*value), math.Log1p(b.min), math.Log1p(b.max))
+			default:
+				b := relative[component]
+				score = minMax(*value, b.min, b.max)
+			}
+			normalized[component] = &score
+		}
+
+		valuation.Score = compositeScore(profile, inputs[i], normalized)
+		scored[i] = valuation
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score.Score > scored[j].Score.Score
+	})
+
+	return scored
+}
+
+// scoreInputs collects the raw input of each component for a valuation; unavailable inputs are nil
+func scoreInputs(valuation ValuationData) map[string]*float64 {
+	apr, stability, tvl := valuation.APR, valuation.Stability, valuation.TVL
+	inputs := map[string]*float64{
+		ScoreComponentAPR:       &apr,
+		ScoreComponentStability: &stability,
+		ScoreComponentTVL:       &tvl,
+		ScoreComponentValuation: nil,
+		ScoreComponentDepeg:     valuation.PremiumBps,
+		ScoreComponentLiquidity: nil,
+	}
+
+	if remarkScore, exists := remarkScores[valuation.Remarks]; exists {
+		inputs[ScoreComponentValuation] = &remarkScore
+	}
+
+	if valuation.Volume24h != nil {
+		volume := *valuation.Volume24h
+		inputs[ScoreComponentLiquidity] = &volume
+	}
+
+	return inputs
+}
+
+// compositeScore weighs the normalized components of a token with a profile
+func compositeScore(profile *ScoringProfile, inputs, normalized map[string]*float64) *CompositeScore {
+	availableWeight := 0.0
+	for component, weight := range profile.Weights {
+		if normalized[component] != nil {
+			availableWeight += weight
+		}
+	}
+
+	score := &CompositeScore{Profile: profile.Name}
+	for _, component := range ScoreComponents {
+		weight := profile.Weights[component]
+		breakdown := ScoreComponent{
+			Name:       component,
+			Weight:     weight,
+			Value:      inputs[component],
+			Normalized: normalized[component],
+		}
+		if normalized[component] != nil && availableWeight > 0 {
+			breakdown.Contribution = *normalized[component] * weight / availableWeight * 100
+			score.Score += breakdown.Contribution
+		}
+		score.Components = append(score.Components, breakdown)
+	}
+
+	return score
+}
+
+// minMax scales value to 0-1 between min and max, returning 0.5 when all values are equal
+func minMax(value, min, max float64) float64 {
+	if max == min {
+		return 0.5
+	}
+	return (value - min) / (max - min)
+}
+
+// isScoreComponent reports whether name is a composite score component
+func isScoreComponent(name string) bool {
+	for _, component := range ScoreComponents {
+		if component == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newScoringProfileFromDB converts a stored scoring profile to the service type
+func newScoringProfileFromDB(profile db.ScoringProfile) ScoringProfile {
+	return ScoringProfile{
+		Name:        profile.Name,
+		Description: profile.Description,
+		Weights:     profile.Weights,
+		UpdatedAt:   profile.UpdatedAt,
+	}
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Valuation remarks in order from most undervalued to most overvalued
+const (
+	RemarkVeryUndervalued = "Very Undervalued"
+	RemarkUndervalued     = "Undervalued"
+	RemarkFairValue       = "Fair Value"
+	RemarkOvervalued      = "Overvalued"
+	RemarkVeryOvervalued  = "Very Overvalued"
+	RemarkUnknown         = "Unknown"
+)
+
+var (
+	// SignalHorizons are the forward windows in days that signals are evaluated over
+	SignalHorizons = []int{7, 30, 90}
+
+	// signalRemarks orders the buckets of a backtest report
+	signalRemarks = []string{RemarkVeryUndervalued, RemarkUndervalued, RemarkFairValue, RemarkOvervalued, RemarkVeryOvervalued, RemarkUnknown}
+)
+
+const (
+	// valuationLookbackDays is the price history a valuation is computed from, as for live valuations
+	valuationLookbackDays = 365
+	// maxSignalStepDays is the longest interval between replayed valuation dates
+	maxSignalStepDays = 90
+)
+
+// ErrInvalidSignalBacktest is returned when signal backtest parameters fail validation
+var ErrInvalidSignalBacktest = errors.New("invalid signal backtest")
+
+// SignalBacktestQuery selects the valuation dates and methodology of a signal backtest
+type SignalBacktestQuery struct {
+	From       time.Time
+	To         time.Time
+	StepDays   int // Days between replayed valuation dates
+	APRMethod  string
+	Thresholds RemarkThresholds
+}
+
+// SignalBacktest reports how the valuation remarks of past dates relate to the returns that followed.
+// Returns are in ETH; excess returns are measured against the average forward return of the same token
+// over all replayed dates, so a bucket with positive excess return outperformed its tokens' norm.
+type SignalBacktest struct {
+	Symbols    []string             `json:"symbols"`
+	From       time.Time            `json:"from"`
+	To         time.Time            `json:"to"`
+	StepDays   int                  `json:"step_days"`
+	APRMethod  string               `json:"apr_method"`
+	Thresholds RemarkThresholds     `json:"thresholds"`
+	Horizons   []int                `json:"horizons"` // Forward windows in days
+	Signals    int                  `json:"signals"`  // Replayed valuations across all tokens
+	Buckets    []SignalBucket       `json:"buckets"`
+	Skipped    map[string]int       `json:"skipped"`   // Dates per token without enough history for a valuation
+	Baselines  map[string][]float64 `json:"baselines"` // Average forward return per token and horizon
+}
+
+// SignalBucket holds the forward performance of the valuations that received one remark
+type SignalBucket struct {
+	Remarks  string         `json:"remarks"`
+	Count    int            `json:"count"`
+	Horizons []HorizonStats `json:"horizons"`
+}
+
+// HorizonStats summarizes the forward returns of a bucket over one horizon. A signal hits when its excess
+// return points the way the remark predicts: positive for undervalued, negative for overvalued remarks.
+type HorizonStats struct {
+	Days            int      `json:"days"`
+	Samples         int      `json:"samples"` // Signals with a price at the end of the horizon
+	AvgReturn       *float64 `json:"avg_return"`
+	AvgExcessReturn *float64 `json:"avg_excess_return"`
+	HitRate         *float64 `json:"hit_rate"` // Null for Fair Value and Unknown, which predict no direction
+}
+
+// valuationSignal is the remark of one replayed valuation and the returns that followed it
+type valuationSignal struct {
+	token   int
+	remarks string
+	returns []*float64 // Forward return per horizon, nil when the horizon ends after the history
+}
+
+// BacktestValuationSignals replays the valuation of each token at every step between from and to using only
+// the daily ETH prices known at that date, then measures the forward returns following each remark
+func (s *ValuationService) BacktestValuationSignals(ctx context.Context, symbols []string, query SignalBacktestQuery) (*SignalBacktest, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	calculator, err := replayCalculator(query.APRMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SignalBacktest{
+		Symbols:    symbols,
+		From:       query.From,
+		To:         query.To,
+		StepDays:   query.StepDays,
+		APRMethod:  query.APRMethod,
+		Thresholds: query.Thresholds,
+		Horizons:   SignalHorizons,
+		Skipped:    map[string]int{},
+		Baselines:  map[string][]float64{},
+	}
+
+	dayMs := (24 * time.Hour).Milliseconds()
+	var signals []valuationSignal
+
+	for token, symbol := range symbols {
+		priceHistory, err := s.GetTokenHistory(ctx, symbol, valuationHistoryQuery(query.From, time.Now().UTC()))
+		if err != nil {
+			return nil, err
+		}
+		closes := dailyCloses(priceHistory)
+
+		closeByDay := make(map[int64]float64, len(closes))
+		for _, point := range closes {
+			closeByDay[point.Timestamp] = point.Price
+		}
+
+		first := len(signals)
+		start := intervalStart(query.From, IntervalDaily)
+		for day := start; !day.After(query.To); day = day.AddDate(0, 0, query.StepDays) {
+			dayStart := day.UnixMilli()
+			price, exists := closeByDay[dayStart]
+			if !exists || price <= 0 {
+				continue
+			}
+
+			valuation, err := calculateValuation(ctx, symbol, valuationWindow(closes, dayStart), 0, calculator, nil, query.Thresholds)
+			if err != nil {
+				report.Skipped[symbol]++
+				continue
+			}
+
+			signal := valuationSignal{token: token, remarks: valuation.Remarks, returns: make([]*float64, len(SignalHorizons))}
+			for h, days := range SignalHorizons {
+				if later, exists := closeByDay[dayStart+int64(days)*dayMs]; exists {
+					forward := later/price - 1
+					signal.returns[h] = &forward
+				}
+			}
+			signals = append(signals, signal)
+		}
+
+		report.Baselines[symbol] = averageForwardReturns(signals[first:])
+	}
+
+	report.Signals = len(signals)
+	for _, remarks := range signalRemarks {
+		report.Buckets = append(report.Buckets, summarizeSignals(remarks, signals, symbols, report.Baselines))
+	}
+
+	return report, nil
+}
+
+// replayCalculator returns the APR calculator used to replay past valuations with a methodology.
+// The legacy methodology logs every calculation, so bulk replays use a quiet copy.
+func replayCalculator(method string) (APRCalculator, error) {
+	if method == APRMethodLegacy {
+		return legacyAPR{quiet: true}, nil
+	}
+	return GetAPRCalculator(method)
+}
+
+// valuationHistoryQuery returns the daily ETH prices a replay of valuations from from to to needs: the range
+// plus the lookback of the first valuation, clamped to the history limit
+func valuationHistoryQuery(from, to time.Time) HistoryQuery {
+	start := from.AddDate(0, 0, -valuationLookbackDays)
+	if earliest := EarliestHistoryStart(); start.Before(earliest) {
+		start = earliest
+	}
+
+	return HistoryQuery{From: start, To: to, Interval: IntervalDaily, Quote: QuoteETH}
+}
+
+// valuationWindow returns the daily closes a valuation on a day sees: the year of prices up to and including
+// the day. Closes must be sorted by time.
+func valuationWindow(closes []PricePoint, day int64) []PricePoint {
+	dayMs := (24 * time.Hour).Milliseconds()
+	start := sort.Search(len(closes), func(i int) bool { return closes[i].Timestamp > day-valuationLookbackDays*dayMs })
+	end := sort.Search(len(closes), func(i int) bool { return closes[i].Timestamp > day })
+	return append([]PricePoint{}, closes[start:end]...)
+}
+
+// Validate checks the range, step, methodology and thresholds of a signal backtest
+func (q SignalBacktestQuery) Validate() error {
+	if q.From.After(q.To) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidSignalBacktest)
+	}
+	if q.To.Sub(q.From) > MaxHistoryDays*24*time.Hour {
+		return fmt.Errorf("%w: range exceeds %d days", ErrInvalidSignalBacktest, MaxHistoryDays)
+	}
+	if q.From.Before(EarliestHistoryStart()) {
+		return fmt.Errorf("%w: from must be within the last %d days", ErrInvalidSignalBacktest, MaxHistoryDays)
+	}
+	if q.StepDays < 1 || q.StepDays > maxSignalStepDays {
+		return fmt.Errorf("%w: step must be between 1 and %d days", ErrInvalidSignalBacktest, maxSignalStepDays)
+	}
+	if q.APRMethod == APRMethodProtocolRate {
+		return fmt.Errorf("%w: %s has no history to replay", ErrUnsupportedAPRMethod, q.APRMethod)
+	}
+	if q.Thresholds.FairValueTolerance < 0 || q.Thresholds.SignificantThreshold <= q.Thresholds.FairValueTolerance {
+		return fmt.Errorf("%w: thresholds must satisfy 0 <= fair_value_tolerance < significant_threshold", ErrInvalidSignalBacktest)
+	}
+	return nil
+}
+
+// dailyCloses returns the last price of each UTC day, keyed at the start of the day and sorted oldest first
+func dailyCloses(points []PricePoint) []PricePoint {
+	var closes []PricePoint
+	for _, point := range sortedByTime(points) {
+		day := intervalStart(time.UnixMilli(point.Timestamp), IntervalDaily).UnixMilli()
+		if len(closes) > 0 && closes[len(closes)-1].Timestamp == day {
+			closes[len(closes)-1].Price = point.Price
+			continue
+		}
+		closes = append(closes, PricePoint{Timestamp: day, Price: point.Price})
+	}
+	return closes
+}
+
+// averageForwardReturns returns the average forward return of signals per horizon, 0 when none has one
+func averageForwardReturns(signals []valuationSignal) []float64 {
+	averages := make([]float64, len(SignalHorizons))
+	for h := range SignalHorizons {
+		sum, count := 0.0, 0
+		for _, signal := range signals {
+			if signal.returns[h] != nil {
+				sum += *signal.returns[h]
+				count++
+			}
+		}
+		if count > 0 {
+			averages[h] = sum / float64(count)
+		}
+	}
+	return averages
+}
+
+// summarizeSignals returns the forward performance of the signals with a remark, measuring excess returns
+// against the baseline of each signal's token
+func summarizeSignals(remarks string, signals []valuationSignal, symbols []string, baselines map[string][]float64) SignalBucket {
+	bucket := SignalBucket{Remarks: remarks}
+	var bucketSignals []valuationSignal
+	for _, signal := range signals {
+		if signal.remarks == remarks {
+			bucketSignals = append(bucketSignals, signal)
+		}
+	}
+	bucket.Count = len(bucketSignals)
+
+	for h, days := range SignalHorizons {
+		stats := HorizonStats{Days: days}
+		var sumReturn, sumExcess float64
+		hits := 0
+		for _, signal := range bucketSignals {
+			if signal.returns[h] == nil {
+				continue
+			}
+			excess := *signal.returns[h] - baselines[symbols[signal.token]][h]
+			stats.Samples++
+			sumReturn += *signal.returns[h]
+			sumExcess += excess
+			if (excess > 0 && isUndervaluedRemark(remarks)) || (excess < 0 && isOvervaluedRemark(remarks)) {
+				hits++
+			}
+		}
+
+		if stats.Samples > 0 {
+			avgReturn := sumReturn / float64(stats.Samples)
+			avgExcess := sumExcess / float64(stats.Samples)
+			stats.AvgReturn, stats.AvgExcessReturn = &avgReturn, &avgExcess
+			if isUndervaluedRemark(remarks) || isOvervaluedRemark(remarks) {
+				hitRate := float64(hits) / float64(stats.Samples)
+				stats.HitRate = &hitRate
+			}
+		}
+		bucket.Horizons = append(bucket.Horizons, stats)
+	}
+
+	return bucket
+}
+
+// isUndervaluedRemark reports whether a remark predicts outperformance
+func isUndervaluedRemark(remarks string) bool {
+	return remarks == RemarkVeryUndervalued || remarks == RemarkUndervalued
+}
+
+// isOvervaluedRemark reports whether a remark predicts underperformance
+func isOvervaluedRemark(remarks string) bool {
+	return remarks == RemarkOvervalued || remarks == RemarkVeryOvervalued
+}
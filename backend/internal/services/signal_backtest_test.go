@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// float64Ptr returns a pointer to an optional metric value
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestDailyCloses(t *testing.T) {
+	hour := time.Hour.Milliseconds()
+	tests := []struct {
+		name   string
+		points []PricePoint
+		want   []PricePoint
+	}{
+		{name: "empty", points: nil, want: nil},
+		{name: "one point", points: []PricePoint{{Timestamp: 5 * hour, Price: 1}}, want: []PricePoint{{Timestamp: 0, Price: 1}}},
+		{
+			name: "last price of each day, unsorted input",
+			points: []PricePoint{
+				{Timestamp: testDayMs + 3*hour, Price: 1.2},
+				{Timestamp: 2 * hour, Price: 1.1},
+				{Timestamp: hour, Price: 1},
+				{Timestamp: testDayMs + hour, Price: 1.15},
+			},
+			want: []PricePoint{{Timestamp: 0, Price: 1.1}, {Timestamp: testDayMs, Price: 1.2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dailyCloses(tt.points); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dailyCloses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValuationWindow(t *testing.T) {
+	var closes []PricePoint
+	for day := int64(0); day < valuationLookbackDays+10; day++ {
+		closes = append(closes, PricePoint{Timestamp: day * testDayMs, Price: 1})
+	}
+
+	tests := []struct {
+		name        string
+		day         int64
+		first, last int64
+	}{
+		{name: "history shorter than a year", day: 5 * testDayMs, first: 0, last: 5 * testDayMs},
+		{name: "year up to the day", day: (valuationLookbackDays + 5) * testDayMs, first: 6 * testDayMs, last: (valuationLookbackDays + 5) * testDayMs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := valuationWindow(closes, tt.day)
+			if window[0].Timestamp != tt.first || window[len(window)-1].Timestamp != tt.last {
+				t.Errorf("valuationWindow() spans %d to %d, want %d to %d", window[0].Timestamp, window[len(window)-1].Timestamp, tt.first, tt.last)
+			}
+		})
+	}
+}
+
+func TestValuationHistoryQueryClampsLookback(t *testing.T) {
+	now := time.Now().UTC()
+	if query := valuationHistoryQuery(now.AddDate(0, 0, -MaxHistoryDays+10), now); !query.From.Equal(EarliestHistoryStart()) {
+		t.Errorf("from = %v, want %v", query.From, EarliestHistoryStart())
+	}
+
+	from := now.AddDate(0, 0, -30)
+	if query := valuationHistoryQuery(from, now); !query.From.Equal(from.AddDate(0, 0, -valuationLookbackDays)) {
+		t.Errorf("from = %v, want %v", query.From, from.AddDate(0, 0, -valuationLookbackDays))
+	}
+}
+
+func TestAverageForwardReturns(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals []valuationSignal
+		want    []float64
+	}{
+		{name: "no signals", signals: nil, want: []float64{0, 0, 0}},
+		{
+			name:    "one signal",
+			signals: []valuationSignal{{returns: []*float64{float64Ptr(0.01), float64Ptr(0.03), nil}}},
+			want:    []float64{0.01, 0.03, 0},
+		},
+		{
+			name: "skips horizons past the history",
+			signals: []valuationSignal{
+				{returns: []*float64{float64Ptr(0.01), float64Ptr(0.02), float64Ptr(0.04)}},
+				{returns: []*float64{float64Ptr(0.03), nil, nil}},
+			},
+			want: []float64{0.02, 0.02, 0.04},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := averageForwardReturns(tt.signals)
+			for h := range tt.want {
+				if !approxEqual(got[h], tt.want[h]) {
+					t.Errorf("averageForwardReturns() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeSignals(t *testing.T) {
+	symbols := []string{"A", "B"}
+	baselines := map[string][]float64{"A": {0.01, 0.02, 0.03}, "B": {0, 0, 0}}
+	signals := []valuationSignal{
+		{token: 0, remarks: RemarkUndervalued, returns: []*float64{float64Ptr(0.03), float64Ptr(0.01), nil}},
+		{token: 1, remarks: RemarkUndervalued, returns: []*float64{float64Ptr(-0.01), float64Ptr(0.02), nil}},
+		{token: 0, remarks: RemarkOvervalued, returns: []*float64{float64Ptr(0), nil, nil}},
+		{token: 1, remarks: RemarkFairValue, returns: []*float64{float64Ptr(0.02), nil, nil}},
+	}
+
+	tests := []struct {
+		name      string
+		remarks   string
+		count     int
+		samples   []int
+		avgReturn []*float64
+		avgExcess []*float64
+		hitRate   []*float64
+	}{
+		{
+			// 7d excess returns of 0.02 and -0.01, 30d of -0.01 and 0.02; no 90d samples
+			name:      "undervalued",
+			remarks:   RemarkUndervalued,
+			count:     2,
+			samples:   []int{2, 2, 0},
+			avgReturn: []*float64{float64Ptr(0.01), float64Ptr(0.015), nil},
+			avgExcess: []*float64{float64Ptr(0.005), float64Ptr(0.005), nil},
+			hitRate:   []*float64{float64Ptr(0.5), float64Ptr(0.5), nil},
+		},
+		{
+			// Underperforming the baseline is a hit for an overvalued remark
+			name:      "overvalued",
+			remarks:   RemarkOvervalued,
+			count:     1,
+			samples:   []int{1, 0, 0},
+			avgReturn: []*float64{float64Ptr(0), nil, nil},
+			avgExcess: []*float64{float64Ptr(-0.01), nil, nil},
+			hitRate:   []*float64{float64Ptr(1), nil, nil},
+		},
+		{
+			name:      "fair value has no hit rate",
+			remarks:   RemarkFairValue,
+			count:     1,
+			samples:   []int{1, 0, 0},
+			avgReturn: []*float64{float64Ptr(0.02), nil, nil},
+			avgExcess: []*float64{float64Ptr(0.02), nil, nil},
+			hitRate:   []*float64{nil, nil, nil},
+		},
+		{
+			name:      "empty bucket",
+			remarks:   RemarkVeryOvervalued,
+			count:     0,
+			samples:   []int{0, 0, 0},
+			avgReturn: []*float64{nil, nil, nil},
+			avgExcess: []*float64{nil, nil, nil},
+			hitRate:   []*float64{nil, nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := summarizeSignals(tt.remarks, signals, symbols, baselines)
+			if bucket.Remarks != tt.remarks || bucket.Count != tt.count || len(bucket.Horizons) != len(SignalHorizons) {
+				t.Fatalf("summarizeSignals() = %+v, want %s bucket of %d with %d horizons", bucket, tt.remarks, tt.count, len(SignalHorizons))
+			}
+			for h, stats := range bucket.Horizons {
+				if stats.Days != SignalHorizons[h] || stats.Samples != tt.samples[h] {
+					t.Errorf("horizon %d: days, samples = (%d, %d), want (%d, %d)", h, stats.Days, stats.Samples, SignalHorizons[h], tt.samples[h])
+				}
+				checkOptional(t, "avg_return", h, stats.AvgReturn, tt.avgReturn[h])
+				checkOptional(t, "avg_excess_return", h, stats.AvgExcessReturn, tt.avgExcess[h])
+				checkOptional(t, "hit_rate", h, stats.HitRate, tt.hitRate[h])
+			}
+		})
+	}
+}
+
+// checkOptional compares an optional metric of a horizon
+func checkOptional(t *testing.T, field string, h int, got, want *float64) {
+	t.Helper()
+	if (got == nil) != (want == nil) || (got != nil && !approxEqual(*got, *want)) {
+		t.Errorf("horizon %d: %s = %v, want %v", h, field, formatOptional(got), formatOptional(want))
+	}
+}
+
+// formatOptional prints an optional metric value rather than its address
+func formatOptional(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func TestSignalBacktestQueryValidate(t *testing.T) {
+	now := time.Now().UTC()
+	valid := SignalBacktestQuery{
+		From:       now.AddDate(0, 0, -30),
+		To:         now,
+		StepDays:   7,
+		APRMethod:  APRMethodLegacy,
+		Thresholds: DefaultRemarkThresholds,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(q *SignalBacktestQuery)
+		wantErr error
+	}{
+		{name: "valid", modify: func(q *SignalBacktestQuery) {}},
+		{name: "single day", modify: func(q *SignalBacktestQuery) { q.From = q.To }},
+		{name: "from after to", modify: func(q *SignalBacktestQuery) { q.From = q.To.Add(time.Hour) }, wantErr: ErrInvalidSignalBacktest},
+		{name: "from past the history limit", modify: func(q *SignalBacktestQuery) { q.From = now.AddDate(0, 0, -MaxHistoryDays-1) }, wantErr: ErrInvalidSignalBacktest},
+		{name: "zero step", modify: func(q *SignalBacktestQuery) { q.StepDays = 0 }, wantErr: ErrInvalidSignalBacktest},
+		{name: "step too long", modify: func(q *SignalBacktestQuery) { q.StepDays = maxSignalStepDays + 1 }, wantErr: ErrInvalidSignalBacktest},
+		{name: "protocol rate", modify: func(q *SignalBacktestQuery) { q.APRMethod = APRMethodProtocolRate }, wantErr: ErrUnsupportedAPRMethod},
+		{
+			name: "tolerance not below the significant threshold",
+			modify: func(q *SignalBacktestQuery) {
+				q.Thresholds.FairValueTolerance = q.Thresholds.SignificantThreshold
+			},
+			wantErr: ErrInvalidSignalBacktest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := valid
+			tt.modify(&query)
+			if err := query.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,578 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metrics strategy rules can test and rank tokens by
+const (
+	StrategyMetricAPR        = "apr"
+	StrategyMetricStability  = "stability"
+	StrategyMetricTVL        = "tvl"
+	StrategyMetricPremiumBps = "premium_bps"
+	StrategyMetricRemarks    = "remarks"
+)
+
+// Comparison operators of strategy conditions. Numeric metrics use the ordering operators;
+// remarks use == and != with value_string, or in and not_in with values.
+const (
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpEqual        = "=="
+	OpNotEqual     = "!="
+	OpIn           = "in"
+	OpNotIn        = "not_in"
+)
+
+const (
+	// maxSnapshotAge is how old a valuation snapshot may be to supply TVL and premium on a simulated day
+	maxSnapshotAge = 7 * 24 * time.Hour
+	// maxSwapCostBps caps the assumed cost of a swap
+	maxSwapCostBps = 1000
+)
+
+// ErrInvalidStrategy is returned when a strategy fails validation
+var ErrInvalidStrategy = errors.New("invalid strategy")
+
+// StrategyCondition compares a metric of a token with a value
+type StrategyCondition struct {
+	Metric      string   `json:"metric" example:"remarks"`
+	Op          string   `json:"op" example:"in"`
+	Value       *float64 `json:"value,omitempty"`
+	ValueString string   `json:"value_string,omitempty"`
+	Values      []string `json:"values,omitempty" example:"Undervalued,Very Undervalued"`
+}
+
+// Strategy is a declarative rotation rule set. At every rebalance date the tokens of the universe that meet
+// all entry conditions are ranked, held tokens meeting all exit conditions are sold, and the free slots up to
+// max_positions are filled with the best ranked candidates. Without exit conditions holdings are rotated
+// into the top candidates at every rebalance. Held tokens are equally weighted; unallocated value stays in ETH.
+type Strategy struct {
+	Symbols      []string            `json:"symbols,omitempty" example:"wstETH,rETH,cbETH"`
+	Entry        []StrategyCondition `json:"entry"`
+	Exit         []StrategyCondition `json:"exit,omitempty"`
+	RankBy       string              `json:"rank_by,omitempty" example:"apr"`
+	RankOrder    string              `json:"rank_order,omitempty" example:"desc"`
+	MaxPositions int                 `json:"max_positions,omitempty" example:"1"`
+	Rebalance    string              `json:"rebalance,omitempty" example:"monthly"`
+	SwapCostBps  float64             `json:"swap_cost_bps,omitempty" example:"10"`
+	APRMethod    string              `json:"apr_method,omitempty" example:"legacy"`
+}
+
+// StrategyBacktestInput selects the strategy, range and starting value of a backtest
+type StrategyBacktestInput struct {
+	Strategy     Strategy
+	From         time.Time
+	To           time.Time
+	InitialValue float64 // In ETH
+}
+
+// StrategyTrade is a swap executed at the daily close of a rebalance date
+type StrategyTrade struct {
+	Timestamp int64   `json:"timestamp"`
+	Symbol    string  `json:"symbol"`
+	Side      string  `json:"side"` // buy or sell
+	Price     float64 `json:"price"`
+	Units     float64 `json:"units"`
+	Value     float64 `json:"value"` // In ETH
+	Cost      float64 `json:"cost"`  // Swap cost in ETH
+}
+
+// StrategyBacktest is the result of running a strategy over stored history. Values are in ETH.
+type StrategyBacktest struct {
+	Strategy        Strategy         `json:"strategy"` // With defaults applied
+	From            time.Time        `json:"from"`
+	To              time.Time        `json:"to"`
+	InitialValue    float64          `json:"initial_value"`
+	FinalValue      float64          `json:"final_value"`
+	TotalReturn     float64          `json:"total_return"`
+	BenchmarkReturn float64          `json:"benchmark_return"` // Equal-weight buy and hold of the tokens priced on the first day
+	Risk            *RiskMetrics     `json:"risk"`
+	Rebalances      int              `json:"rebalances"` // Dates the rules were evaluated
+	TradeCount      int              `json:"trade_count"`
+	TradedValue     float64          `json:"traded_value"`
+	TotalCosts      float64          `json:"total_costs"`
+	Exposure        float64          `json:"exposure"` // Share of days holding at least one token
+	Holdings        []string         `json:"holdings"` // Tokens held at the end
+	Trades          []StrategyTrade  `json:"trades"`
+	EquityCurve     []PortfolioPoint `json:"equity_curve"`
+}
+
+// strategyMetrics are the metrics of one token on one simulated day
+type strategyMetrics struct {
+	numbers map[string]*float64
+	remarks string
+}
+
+// strategyToken holds the series of one token of a backtest universe
+type strategyToken struct {
+	symbol     string
+	closes     []PricePoint // Daily closes, oldest first
+	closeByDay map[int64]float64
+	snapshots  []ValuationData // Stored valuations, oldest first
+}
+
+// BacktestStrategy runs a strategy over the stored daily ETH prices and valuation snapshots of its universe.
+// APR, stability and remarks are recomputed at each rebalance from the year of prices known at that date;
+// TVL and premium come from the latest valuation snapshot of the prior week.
+func (s *ValuationService) BacktestStrategy(ctx context.Context, input StrategyBacktestInput) (*StrategyBacktest, error) {
+	strategy, err := normalizeStrategy(input.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if !input.From.Before(input.To) {
+		return nil, fmt.Errorf("%w: start date must be before end date", ErrInvalidStrategy)
+	}
+	initialValue := input.InitialValue
+	if initialValue == 0 {
+		initialValue = 1
+	}
+	if initialValue < 0 || math.IsNaN(initialValue) || math.IsInf(initialValue, 0) {
+		return nil, fmt.Errorf("%w: initial value must be positive", ErrInvalidStrategy)
+	}
+
+	calculator, err := replayCalculator(strategy.APRMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*strategyToken, len(strategy.Symbols))
+	days := map[int64]bool{}
+	for i, symbol := range strategy.Symbols {
+		priceHistory, err := s.GetTokenHistory(ctx, symbol, valuationHistoryQuery(input.From, input.To))
+		if err != nil {
+			return nil, err
+		}
+		snapshots, err := s.GetValuationHistory(ctx, symbol, input.From.Add(-maxSnapshotAge), input.To)
+		if err != nil {
+			return nil, err
+		}
+
+		token := &strategyToken{symbol: symbol, closes: dailyCloses(priceHistory), closeByDay: map[int64]float64{}, snapshots: snapshots}
+		for _, point := range token.closes {
+			token.closeByDay[point.Timestamp] = point.Price
+			if !time.UnixMilli(point.Timestamp).Before(intervalStart(input.From, IntervalDaily)) {
+				days[point.Timestamp] = true
+			}
+		}
+		tokens[i] = token
+	}
+
+	timeline := make([]int64, 0, len(days))
+	for day := range days {
+		timeline = append(timeline, day)
+	}
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i] < timeline[j] })
+	if len(timeline) < 2 {
+		return nil, fmt.Errorf("%w: fewer than two days of prices in the requested range", ErrInsufficientPriceData)
+	}
+
+	result := &StrategyBacktest{
+		Strategy:     strategy,
+		From:         time.UnixMilli(timeline[0]).UTC(),
+		To:           time.UnixMilli(timeline[len(timeline)-1]).UTC(),
+		InitialValue: initialValue,
+		Holdings:     []string{},
+		Trades:       []StrategyTrade{},
+	}
+
+	cash := initialValue
+	units := make([]float64, len(tokens))
+	lastPrices := make([]float64, len(tokens))
+	firstPrices := make([]float64, len(tokens))
+	daysInvested := 0
+
+	for d, day := range timeline {
+		for i, token := range tokens {
+			if price, exists := token.closeByDay[day]; exists && price > 0 {
+				lastPrices[i] = price
+				if firstPrices[i] == 0 && d == 0 {
+					firstPrices[i] = price
+				}
+			}
+		}
+
+		if d == 0 || shouldRebalance(strategy.Rebalance, 0, timeline[d-1], day, nil, nil, 0) {
+			result.Rebalances++
+			targets := selectStrategyHoldings(ctx, strategy, calculator, tokens, units, lastPrices, day)
+			cash = executeStrategyTrades(result, strategy, tokens, units, lastPrices, targets, cash, day)
+		}
+
+		value := cash
+		invested := false
+		for i := range tokens {
+			if units[i] > 0 {
+				value += units[i] * lastPrices[i]
+				invested = true
+			}
+		}
+		if invested {
+			daysInvested++
+		}
+		result.EquityCurve = append(result.EquityCurve, PortfolioPoint{Timestamp: day, Value: value})
+	}
+
+	result.FinalValue = result.EquityCurve[len(result.EquityCurve)-1].Value
+	result.TotalReturn = result.FinalValue/initialValue - 1
+	result.TradeCount = len(result.Trades)
+	result.Exposure = float64(daysInvested) / float64(len(timeline))
+	for i, token := range tokens {
+		if units[i] > 0 {
+			result.Holdings = append(result.Holdings, token.symbol)
+		}
+	}
+
+	// Benchmark: equal weights of the tokens priced on the first day, never rebalanced
+	benchmarkCount := 0
+	benchmarkReturn := 0.0
+	for i := range tokens {
+		if firstPrices[i] > 0 {
+			benchmarkReturn += lastPrices[i]/firstPrices[i] - 1
+			benchmarkCount++
+		}
+	}
+	if benchmarkCount > 0 {
+		result.BenchmarkReturn = benchmarkReturn / float64(benchmarkCount)
+	}
+
+	equityPoints := make([]PricePoint, len(result.EquityCurve))
+	for i, point := range result.EquityCurve {
+		equityPoints[i] = PricePoint{Timestamp: point.Timestamp, Price: point.Value}
+	}
+	if risk, err := CalculateRiskMetrics(equityPoints, BaseStakingRate()); err == nil {
+		risk.Quote = QuoteETH
+		risk.From, risk.To = result.From, result.To
+		result.Risk = risk
+	}
+
+	return result, nil
+}
+
+// selectStrategyHoldings applies the exit and entry rules on a day and returns the tokens to hold
+func selectStrategyHoldings(ctx context.Context, strategy Strategy, calculator APRCalculator, tokens []*strategyToken, units, prices []float64, day int64) []bool {
+	metrics := make([]*strategyMetrics, len(tokens))
+	for i, token := range tokens {
+		if _, priced := token.closeByDay[day]; priced {
+			metrics[i] = token.metricsAt(ctx, calculator, day)
+		}
+	}
+
+	targets := make([]bool, len(tokens))
+	positions := 0
+
+	// Keep held tokens unless an exit rule fires or they can no longer be priced
+	if len(strategy.Exit) > 0 {
+		for i := range tokens {
+			if units[i] > 0 && metrics[i] != nil && !metrics[i].matchesAll(strategy.Exit) {
+				targets[i] = true
+				positions++
+			}
+		}
+	}
+
+	var candidates []int
+	for i := range tokens {
+		if !targets[i] && metrics[i] != nil && metrics[i].matchesAll(strategy.Entry) {
+			candidates = append(candidates, i)
+		}
+	}
+
+	// Rank candidates, leaving those without the ranking metric last
+	sort.SliceStable(candidates, func(a, b int) bool {
+		x := metrics[candidates[a]].numbers[strategy.RankBy]
+		y := metrics[candidates[b]].numbers[strategy.RankBy]
+		if x == nil || y == nil {
+			return x != nil
+		}
+		if strategy.RankOrder == "asc" {
+			return *x < *y
+		}
+		return *x > *y
+	})
+
+	for _, i := range candidates {
+		if positions >= strategy.MaxPositions {
+			break
+		}
+		targets[i] = true
+		positions++
+	}
+
+	return targets
+}
+
+// executeStrategyTrades moves the portfolio to equal weights of the target tokens at the day's prices when
+// the set of holdings changes, recording the trades and charging the swap cost. Tokens without a positive
+// price are neither bought nor sold. It returns the new cash balance.
+func executeStrategyTrades(result *StrategyBacktest, strategy Strategy, tokens []*strategyToken, units, prices []float64, targets []bool, cash float64, day int64) float64 {
+	changed := false
+	positions := 0
+	for i := range tokens {
+		if prices[i] <= 0 {
+			continue
+		}
+		if targets[i] != (units[i] > 0) {
+			changed = true
+		}
+		if targets[i] {
+			positions++
+		}
+	}
+	if !changed {
+		return cash
+	}
+
+	value := cash
+	for i := range tokens {
+		if prices[i] > 0 {
+			value += units[i] * prices[i]
+		}
+	}
+
+	// Swap costs are charged on the traded value, which depends on the position size net of the costs.
+	// A few fixed-point rounds settle it to well below rounding error for any realistic cost.
+	costRate := strategy.SwapCostBps / 10000
+	targetValues := make([]float64, len(tokens))
+	net := value
+	for round := 0; round < 5; round++ {
+		traded := 0.0
+		for i := range tokens {
+			targetValues[i] = 0
+			if prices[i] <= 0 {
+				continue
+			}
+			if targets[i] {
+				targetValues[i] = net / float64(positions)
+			}
+			traded += math.Abs(targetValues[i] - units[i]*prices[i])
+		}
+		net = value - traded*costRate
+	}
+
+	cash = value
+	for i, token := range tokens {
+		if prices[i] <= 0 {
+			continue
+		}
+		current := units[i] * prices[i]
+		delta := targetValues[i] - current
+		if math.Abs(delta) < 1e-12 {
+			cash -= current
+			continue
+		}
+
+		side := "buy"
+		if delta < 0 {
+			side = "sell"
+		}
+		cost := math.Abs(delta) * costRate
+		result.Trades = append(result.Trades, StrategyTrade{
+			Timestamp: day,
+			Symbol:    token.symbol,
+			Side:      side,
+			Price:     prices[i],
+			Units:     math.Abs(delta) / prices[i],
+			Value:     math.Abs(delta),
+			Cost:      cost,
+		})
+		result.TradedValue += math.Abs(delta)
+		result.TotalCosts += cost
+
+		units[i] = targetValues[i] / prices[i]
+		cash -= targetValues[i] + cost
+	}
+
+	// Fully invested portfolios keep no dust from rounding
+	if positions > 0 && math.Abs(cash) < 1e-9*value {
+		cash = 0
+	}
+	return cash
+}
+
+// metricsAt computes the metrics of a token on a day from the prices and snapshots known by its close
+func (t *strategyToken) metricsAt(ctx context.Context, calculator APRCalculator, day int64) *strategyMetrics {
+	metrics := &strategyMetrics{numbers: map[string]*float64{}, remarks: RemarkUnknown}
+
+	if valuation, err := calculateValuation(ctx, t.symbol, valuationWindow(t.closes, day), 0, calculator, nil, DefaultRemarkThresholds); err == nil {
+		apr, stability := valuation.APR, valuation.Stability
+		metrics.numbers[StrategyMetricAPR] = &apr
+		metrics.numbers[StrategyMetricStability] = &stability
+		metrics.remarks = valuation.Remarks
+	}
+
+	// Latest snapshot taken before the end of the day and no older than maxSnapshotAge
+	dayEnd := time.UnixMilli(day).Add(24 * time.Hour)
+	i := sort.Search(len(t.snapshots), func(i int) bool { return !t.snapshots[i].LastUpdated.Before(dayEnd) })
+	if i > 0 && dayEnd.Sub(t.snapshots[i-1].LastUpdated) <= maxSnapshotAge {
+		snapshot := t.snapshots[i-1]
+		tvl := snapshot.TVL
+		metrics.numbers[StrategyMetricTVL] = &tvl
+		metrics.numbers[StrategyMetricPremiumBps] = snapshot.PremiumBps
+	}
+
+	return metrics
+}
+
+// matchesAll reports whether the metrics meet every condition. Conditions on missing metrics fail.
+func (m *strategyMetrics) matchesAll(conditions []StrategyCondition) bool {
+	for _, condition := range conditions {
+		if !m.matches(condition) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *strategyMetrics) matches(condition StrategyCondition) bool {
+	if condition.Metric == StrategyMetricRemarks {
+		switch condition.Op {
+		case OpEqual:
+			return m.remarks == condition.ValueString
+		case OpNotEqual:
+			return m.remarks != condition.ValueString
+		case OpIn, OpNotIn:
+			found := false
+			for _, value := range condition.Values {
+				if m.remarks == value {
+					found = true
+				}
+			}
+			return found == (condition.Op == OpIn)
+		}
+		return false
+	}
+
+	value := m.numbers[condition.Metric]
+	if value == nil {
+		return false
+	}
+
+	switch condition.Op {
+	case OpGreater:
+		return *value > *condition.Value
+	case OpGreaterEqual:
+		return *value >= *condition.Value
+	case OpLess:
+		return *value < *condition.Value
+	case OpLessEqual:
+		return *value <= *condition.Value
+	case OpEqual:
+		return *value == *condition.Value
+	case OpNotEqual:
+		return *value != *condition.Value
+	}
+	return false
+}
+
+// normalizeStrategy validates a strategy and applies its defaults: rank by APR descending, one position,
+// monthly rebalancing and the legacy APR methodology
+func normalizeStrategy(strategy Strategy) (Strategy, error) {
+	if len(strategy.Symbols) == 0 {
+		return strategy, fmt.Errorf("%w: the universe has no tokens", ErrInvalidStrategy)
+	}
+	if len(strategy.Symbols) > maxPortfolioTokens {
+		return strategy, fmt.Errorf("%w: the universe is limited to %d tokens", ErrInvalidStrategy, maxPortfolioTokens)
+	}
+
+	for _, conditions := range [][]StrategyCondition{strategy.Entry, strategy.Exit} {
+		for _, condition := range conditions {
+			if err := condition.validate(); err != nil {
+				return strategy, err
+			}
+		}
+	}
+
+	if strategy.RankBy == "" {
+		strategy.RankBy = StrategyMetricAPR
+	}
+	if !isNumericStrategyMetric(strategy.RankBy) {
+		return strategy, fmt.Errorf("%w: cannot rank by %s (supported: apr, stability, tvl, premium_bps)", ErrInvalidStrategy, strategy.RankBy)
+	}
+	if strategy.RankOrder == "" {
+		strategy.RankOrder = "desc"
+	}
+	if strategy.RankOrder != "asc" && strategy.RankOrder != "desc" {
+		return strategy, fmt.Errorf("%w: rank order must be asc or desc", ErrInvalidStrategy)
+	}
+
+	if strategy.MaxPositions == 0 {
+		strategy.MaxPositions = 1
+	}
+	if strategy.MaxPositions < 0 || strategy.MaxPositions > len(strategy.Symbols) {
+		return strategy, fmt.Errorf("%w: max positions must be between 1 and the number of tokens", ErrInvalidStrategy)
+	}
+
+	if strategy.Rebalance == "" {
+		strategy.Rebalance = RebalanceMonthly
+	}
+	switch strategy.Rebalance {
+	case RebalanceDaily, RebalanceWeekly, RebalanceMonthly, RebalanceQuarterly:
+	default:
+		return strategy, fmt.Errorf("%w: unsupported rebalance frequency %s (supported: daily, weekly, monthly, quarterly)", ErrInvalidStrategy, strategy.Rebalance)
+	}
+
+	if strategy.SwapCostBps < 0 || strategy.SwapCostBps > maxSwapCostBps || math.IsNaN(strategy.SwapCostBps) {
+		return strategy, fmt.Errorf("%w: swap cost must be between 0 and %d bps", ErrInvalidStrategy, maxSwapCostBps)
+	}
+
+	aprMethod, err := ParseAPRMethod(strategy.APRMethod)
+	if err != nil {
+		return strategy, err
+	}
+	if aprMethod == APRMethodProtocolRate {
+		return strategy, fmt.Errorf("%w: %s has no history to replay", ErrUnsupportedAPRMethod, aprMethod)
+	}
+	strategy.APRMethod = aprMethod
+
+	return strategy, nil
+}
+
+// validate checks that a condition's operator and value suit its metric
+func (c StrategyCondition) validate() error {
+	if c.Metric == StrategyMetricRemarks {
+		switch c.Op {
+		case OpEqual, OpNotEqual:
+			if c.ValueString == "" {
+				return fmt.Errorf("%w: remarks %s needs value_string", ErrInvalidStrategy, c.Op)
+			}
+		case OpIn, OpNotIn:
+			if len(c.Values) == 0 {
+				return fmt.Errorf("%w: remarks %s needs values", ErrInvalidStrategy, c.Op)
+			}
+		default:
+			return fmt.Errorf("%w: unsupported operator %s for remarks (supported: ==, !=, in, not_in)", ErrInvalidStrategy, c.Op)
+		}
+		return nil
+	}
+
+	if !isNumericStrategyMetric(c.Metric) {
+		return fmt.Errorf("%w: unsupported metric %s (supported: apr, stability, tvl, premium_bps, remarks)", ErrInvalidStrategy, c.Metric)
+	}
+	switch c.Op {
+	case OpGreater, OpGreaterEqual, OpLess, OpLessEqual, OpEqual, OpNotEqual:
+	default:
+		return fmt.Errorf("%w: unsupported operator %s for %s (supported: %s)", ErrInvalidStrategy, c.Op, c.Metric,
+			strings.Join([]string{OpGreater, OpGreaterEqual, OpLess, OpLessEqual, OpEqual, OpNotEqual}, ", "))
+	}
+	if c.Value == nil || math.IsNaN(*c.Value) {
+		return fmt.Errorf("%w: %s %s needs a numeric value", ErrInvalidStrategy, c.Metric, c.Op)
+	}
+	return nil
+}
+
+// isNumericStrategyMetric reports whether a metric is a number that can be compared and ranked
+func isNumericStrategyMetric(metric string) bool {
+	switch metric {
+	case StrategyMetricAPR, StrategyMetricStability, StrategyMetricTVL, StrategyMetricPremiumBps:
+		return true
+	}
+	return false
+}
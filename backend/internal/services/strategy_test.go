@@ -0,0 +1,96 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExecuteStrategyTrades(t *testing.T) {
+	tests := []struct {
+		name      string
+		units     []float64
+		prices    []float64
+		targets   []bool
+		cash      float64
+		wantUnits []float64
+		wantCash  float64
+		wantCount int
+	}{
+		{
+			name:      "buys targets with equal weights",
+			units:     []float64{0, 0},
+			prices:    []float64{1, 2},
+			targets:   []bool{true, true},
+			cash:      10,
+			wantUnits: []float64{5, 2.5},
+			wantCash:  0,
+			wantCount: 2,
+		},
+		{
+			name:      "skips a target without a price",
+			units:     []float64{0, 0},
+			prices:    []float64{1, 0},
+			targets:   []bool{true, true},
+			cash:      10,
+			wantUnits: []float64{10, 0},
+			wantCash:  0,
+			wantCount: 1,
+		},
+		{
+			name:      "skips a target with a negative price",
+			units:     []float64{0, 0},
+			prices:    []float64{-1, 2},
+			targets:   []bool{true, true},
+			cash:      10,
+			wantUnits: []float64{0, 5},
+			wantCash:  0,
+			wantCount: 1,
+		},
+		{
+			name:      "keeps cash when no target has a price",
+			units:     []float64{0, 0},
+			prices:    []float64{0, 0},
+			targets:   []bool{true, true},
+			cash:      10,
+			wantUnits: []float64{0, 0},
+			wantCash:  10,
+			wantCount: 0,
+		},
+		{
+			name:      "sells a dropped holding",
+			units:     []float64{4, 0},
+			prices:    []float64{2, 1},
+			targets:   []bool{false, true},
+			cash:      0,
+			wantUnits: []float64{0, 8},
+			wantCash:  0,
+			wantCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := make([]*strategyToken, len(tt.units))
+			for i := range tokens {
+				tokens[i] = &strategyToken{symbol: string(rune('A' + i))}
+			}
+			units := append([]float64{}, tt.units...)
+			result := &StrategyBacktest{}
+
+			cash := executeStrategyTrades(result, Strategy{}, tokens, units, tt.prices, tt.targets, tt.cash, 0)
+
+			if math.Abs(cash-tt.wantCash) > 1e-9 {
+				t.Errorf("cash = %v, want %v", cash, tt.wantCash)
+			}
+			for i := range units {
+				if math.IsNaN(units[i]) || math.IsInf(units[i], 0) || math.Abs(units[i]-tt.wantUnits[i]) > 1e-9 {
+					t.Errorf("units = %v, want %v", units, tt.wantUnits)
+					break
+				}
+			}
+			if len(result.Trades) != tt.wantCount {
+				t.Errorf("trades = %d, want %d", len(result.Trades), tt.wantCount)
+			}
+		})
+	}
+}
@@ -1,86 +1,375 @@
-package services
-
-import (
-	"context"
-	"fmt"
-
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
-)
-
-// TokenService handles token-related business logic
-type TokenService struct {
-}
-
-// NewTokenService creates a new token service
-func NewTokenService() *TokenService {
-	return &TokenService{}
-}
-
-// Token represents a token entity
-type Token struct {
-	ID             int    `json:"id"`
-	Symbol         string `json:"symbol"`
-	Name           string `json:"name"`
-	ContractAddress string `json:"contract_address"`
-	Decimals       int    `json:"decimals"`
-	Blockchain     string `json:"blockchain"`
-	IsActive       bool   `json:"is_active"`
-}
-
-// GetAllTokens retrieves all active tokens
-func (s *TokenService) GetAllTokens(ctx context.Context) ([]Token, error) {
-	dbTokens, err := db.GetAllTokens()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get tokens from database: %w", err)
-	}
-
-	// Convert db models to service models
-	tokens := make([]Token, len(dbTokens))
-	for i, dbToken := range dbTokens {
-		tokens[i] = Token{
-			ID:             dbToken.ID,
-			Symbol:         dbToken.Symbol,
-			Name:           dbToken.Name,
-			ContractAddress: dbToken.ContractAddress,
-			Decimals:       dbToken.Decimals,
-			Blockchain:     dbToken.Blockchain,
-			IsActive:       dbToken.IsActive,
-		}
-	}
-
-	return tokens, nil
-}
-
-// GetTokenBySymbol retrieves a token by its symbol
-func (s *TokenService) GetTokenBySymbol(ctx context.Context, symbol string) (*Token, error) {
-	dbToken, err := db.GetTokenBySymbol(symbol)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token by symbol: %w", err)
-	}
-
-	token := &Token{
-		ID:             dbToken.ID,
-		Symbol:         dbToken.Symbol,
-		Name:           dbToken.Name,
-		ContractAddress: dbToken.ContractAddress,
-		Decimals:       dbToken.Decimals,
-		Blockchain:     dbToken.Blockchain,
-		IsActive:       dbToken.IsActive,
-	}
-
-	return token, nil
-}
-
-// ValidateTokenExists checks if a token exists and is active
-func (s *TokenService) ValidateTokenExists(ctx context.Context, symbol string) error {
-	token, err := s.GetTokenBySymbol(ctx, symbol)
-	if err != nil {
-		return fmt.Errorf("token validation failed: %w", err)
-	}
-
-	if !token.IsActive {
-		return fmt.Errorf("token %s is not active", symbol)
-	}
-
-	return nil
-}
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidToken is returned when token input fails validation
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrTokenNotFound is returned when no token matches the requested symbol
+var ErrTokenNotFound = db.ErrTokenNotFound
+
+// ErrTokenExists is returned when a token with the same symbol or contract address already exists
+var ErrTokenExists = db.ErrTokenExists
+
+// ErrDeploymentNotFound is returned when a token has no deployment on the requested chain
+var ErrDeploymentNotFound = db.ErrDeploymentNotFound
+
+// ErrDeploymentExists is returned when the token already has a deployment on the chain or the address is registered
+var ErrDeploymentExists = db.ErrDeploymentExists
+
+// TokenService handles token-related business logic
+type TokenService struct {
+	chains *ChainRegistry
+}
+
+// NewTokenService creates a new token service
+func NewTokenService(chains *ChainRegistry) *TokenService {
+	return &TokenService{
+		chains: chains,
+	}
+}
+
+// Token represents a token entity
+type Token struct {
+	ID              int    `json:"id"`
+	Symbol          string `json:"symbol"`
+	Name            string `json:"name"`
+	ContractAddress string `json:"contract_address"`
+	Decimals        int    `json:"decimals"`
+	Blockchain      string `json:"blockchain"`
+	CoinGeckoID     string `json:"coingecko_id"`
+	IsActive        bool   `json:"is_active"`
+	IsCustodial     bool   `json:"is_custodial"` // Staking is run by a centralized custodian
+
+	// Bridged deployments on chains other than the canonical Blockchain
+	Deployments []TokenDeployment `json:"deployments"`
+}
+
+// TokenDeployment represents a bridged deployment of a token on a non-canonical chain
+type TokenDeployment struct {
+	Blockchain      string `json:"blockchain"`
+	ContractAddress string `json:"contract_address"`
+	Decimals        int    `json:"decimals"`
+	EscrowAddress   string `json:"escrow_address,omitempty"` // Bridge escrow on the canonical chain locking the backing tokens
+}
+
+// TokenDeploymentInput represents the deployment fields accepted by the admin API.
+// Decimals default to the on-chain value when omitted.
+type TokenDeploymentInput struct {
+	Blockchain      string `json:"blockchain"`
+	ContractAddress string `json:"contract_address"`
+	Decimals        *int   `json:"decimals,omitempty"`
+	EscrowAddress   string `json:"escrow_address,omitempty"`
+}
+
+// TokenInput represents the token fields accepted by the admin API.
+// Decimals and name default to the on-chain values when omitted, and the CoinGecko ID
+// is resolved from the contract address when omitted.
+type TokenInput struct {
+	Symbol          string `json:"symbol"`
+	Name            string `json:"name"`
+	ContractAddress string `json:"contract_address"`
+	Decimals        *int   `json:"decimals,omitempty"`
+	Blockchain      string `json:"blockchain"`
+	CoinGeckoID     string `json:"coingecko_id,omitempty"`
+	IsActive        *bool  `json:"is_active,omitempty"`
+	IsCustodial     *bool  `json:"is_custodial,omitempty"`
+}
+
+// GetAllTokens retrieves all active tokens
+func (s *TokenService) GetAllTokens(ctx context.Context) ([]Token, error) {
+	dbTokens, err := db.GetAllTokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tokens from database: %w", err)
+	}
+
+	dbDeployments, err := db.GetAllTokenDeployments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token deployments from database: %w", err)
+	}
+
+	deploymentsByToken := map[int][]db.TokenDeployment{}
+	for _, dbDeployment := range dbDeployments {
+		deploymentsByToken[dbDeployment.TokenID] = append(deploymentsByToken[dbDeployment.TokenID], dbDeployment)
+	}
+
+	// Convert db models to service models
+	tokens := make([]Token, len(dbTokens))
+	for i, dbToken := range dbTokens {
+		tokens[i] = newTokenFromDB(dbToken)
+		tokens[i].Deployments = newDeploymentsFromDB(deploymentsByToken[dbToken.ID])
+	}
+
+	return tokens, nil
+}
+
+// GetTokenBySymbol retrieves a token by its symbol
+func (s *TokenService) GetTokenBySymbol(ctx context.Context, symbol string) (*Token, error) {
+	dbToken, err := db.GetTokenBySymbol(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token by symbol: %w", err)
+	}
+
+	dbDeployments, err := db.GetTokenDeployments(dbToken.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token deployments: %w", err)
+	}
+
+	token := newTokenFromDB(*dbToken)
+	token.Deployments = newDeploymentsFromDB(dbDeployments)
+	return &token, nil
+}
+
+// ValidateTokenExists checks if a token exists and is active
+func (s *TokenService) ValidateTokenExists(ctx context.Context, symbol string) error {
+	token, err := s.GetTokenBySymbol(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("token validation failed: %w", err)
+	}
+
+	if !token.IsActive {
+		return fmt.Errorf("token %s is not active", symbol)
+	}
+
+	return nil
+}
+
+// CreateToken validates the token against its contract and registers it
+func (s *TokenService) CreateToken(ctx context.Context, input TokenInput) (*Token, error) {
+	dbToken, err := s.validateTokenInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := db.CreateToken(*dbToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	token := newTokenFromDB(*created)
+	return &token, nil
+}
+
+// UpdateToken validates the new token fields against the contract and updates the token
+func (s *TokenService) UpdateToken(ctx context.Context, symbol string, input TokenInput) (*Token, error) {
+	input.Symbol = symbol
+
+	dbToken, err := s.validateTokenInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Omitted flags keep their stored values so unrelated edits do not reactivate or reclassify the token
+	updated, err := db.UpdateToken(symbol, *dbToken, input.IsActive, input.IsCustodial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update token: %w", err)
+	}
+
+	dbDeployments, err := db.GetTokenDeployments(updated.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token deployments: %w", err)
+	}
+
+	invalidateTokenCache(ctx, symbol)
+
+	token := newTokenFromDB(*updated)
+	token.Deployments = newDeploymentsFromDB(dbDeployments)
+	return &token, nil
+}
+
+// DeleteToken deactivates a token so it is no longer tracked, keeping its stored history
+func (s *TokenService) DeleteToken(ctx context.Context, symbol string) error {
+	if err := db.DeactivateToken(symbol); err != nil {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+
+	invalidateTokenCache(ctx, symbol)
+	return nil
+}
+
+// CreateTokenDeployment validates a bridged deployment against its contract and registers it for the token
+func (s *TokenService) CreateTokenDeployment(ctx context.Context, symbol string, input TokenDeploymentInput) (*TokenDeployment, error) {
+	token, err := db.GetTokenBySymbol(symbol)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token by symbol: %w", err)
+	}
+
+	if strings.EqualFold(input.Blockchain, token.Blockchain) {
+		return nil, fmt.Errorf("%w: %s is the canonical chain of %s", ErrInvalidToken, token.Blockchain, symbol)
+	}
+	if input.EscrowAddress != "" && !common.IsHexAddress(input.EscrowAddress) {
+		return nil, fmt.Errorf("%w: escrow_address %q is not a valid address", ErrInvalidToken, input.EscrowAddress)
+	}
+
+	metadata, err := s.validateContract(ctx, input.Blockchain, input.ContractAddress, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals := metadata.Decimals
+	if input.Decimals != nil && *input.Decimals != metadata.Decimals {
+		return nil, fmt.Errorf("%w: decimals %d do not match on-chain decimals %d", ErrInvalidToken, *input.Decimals, metadata.Decimals)
+	}
+
+	created, err := db.CreateTokenDeployment(symbol, db.TokenDeployment{
+		Blockchain:      strings.ToLower(input.Blockchain),
+		ContractAddress: strings.ToLower(input.ContractAddress),
+		Decimals:        decimals,
+		EscrowAddress:   strings.ToLower(input.EscrowAddress),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token deployment: %w", err)
+	}
+
+	invalidateTokenCache(ctx, symbol)
+
+	deployment := newDeploymentFromDB(*created)
+	return &deployment, nil
+}
+
+// DeleteTokenDeployment removes the bridged deployment of a token on a chain
+func (s *TokenService) DeleteTokenDeployment(ctx context.Context, symbol, blockchain string) error {
+	if err := db.DeleteTokenDeployment(symbol, strings.ToLower(blockchain)); err != nil {
+		return fmt.Errorf("failed to delete token deployment: %w", err)
+	}
+
+	invalidateTokenCache(ctx, symbol)
+	return nil
+}
+
+// validateContract reads the ERC20 metadata of a contract on a chain and checks its symbol
+func (s *TokenService) validateContract(ctx context.Context, blockchain, contractAddress, symbol string) (*ERC20Metadata, error) {
+	if !common.IsHexAddress(contractAddress) {
+		return nil, fmt.Errorf("%w: contract_address %q is not a valid address", ErrInvalidToken, contractAddress)
+	}
+
+	fetcher, err := s.chains.Dial(blockchain)
+	if errors.Is(err, ErrUnsupportedChain) {
+		return nil, fmt.Errorf("%w: unsupported blockchain %q", ErrInvalidToken, blockchain)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contract reader: %w", err)
+	}
+	defer fetcher.Close()
+
+	metadata, err := fetcher.FetchERC20Metadata(ctx, contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read ERC20 metadata from %s on %s: %v", ErrInvalidToken, contractAddress, blockchain, err)
+	}
+
+	// Symbols are compared case-insensitively since the registry uses its own casing (e.g. CBETH for cbETH)
+	if !strings.EqualFold(metadata.Symbol, symbol) {
+		return nil, fmt.Errorf("%w: symbol %q does not match on-chain symbol %q", ErrInvalidToken, symbol, metadata.Symbol)
+	}
+
+	return metadata, nil
+}
+
+// validateTokenInput checks the input against the contract's ERC20 name, symbol and decimals.
+// Omitted is_active and is_custodial default to true and false, which only applies to new tokens.
+func (s *TokenService) validateTokenInput(ctx context.Context, input TokenInput) (*db.Token, error) {
+	if input.Symbol == "" {
+		return nil, fmt.Errorf("%w: symbol is required", ErrInvalidToken)
+	}
+
+	blockchain := strings.ToLower(input.Blockchain)
+	if blockchain == "" {
+		blockchain = "ethereum"
+	}
+
+	metadata, err := s.validateContract(ctx, blockchain, input.ContractAddress, input.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	name := input.Name
+	if name == "" {
+		name = metadata.Name
+	} else if !strings.EqualFold(name, metadata.Name) {
+		return nil, fmt.Errorf("%w: name %q does not match on-chain name %q", ErrInvalidToken, name, metadata.Name)
+	}
+
+	decimals := metadata.Decimals
+	if input.Decimals != nil && *input.Decimals != metadata.Decimals {
+		return nil, fmt.Errorf("%w: decimals %d do not match on-chain decimals %d", ErrInvalidToken, *input.Decimals, metadata.Decimals)
+	}
+
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	isCustodial := false
+	if input.IsCustodial != nil {
+		isCustodial = *input.IsCustodial
+	}
+
+	return &db.Token{
+		Symbol:          input.Symbol,
+		Name:            name,
+		ContractAddress: strings.ToLower(input.ContractAddress),
+		Decimals:        decimals,
+		Blockchain:      blockchain,
+		CoinGeckoID:     input.CoinGeckoID,
+		IsActive:        isActive,
+		IsCustodial:     isCustodial,
+	}, nil
+}
+
+// newTokenFromDB converts a db token model to a service token model
+func newTokenFromDB(dbToken db.Token) Token {
+	return Token{
+		ID:              dbToken.ID,
+		Symbol:          dbToken.Symbol,
+		Name:            dbToken.Name,
+		ContractAddress: dbToken.ContractAddress,
+		Decimals:        dbToken.Decimals,
+		Blockchain:      dbToken.Blockchain,
+		CoinGeckoID:     dbToken.CoinGeckoID,
+		IsActive:        dbToken.IsActive,
+		IsCustodial:     dbToken.IsCustodial,
+		Deployments:     []TokenDeployment{},
+	}
+}
+
+// newDeploymentFromDB converts a db token deployment to a service token deployment
+func newDeploymentFromDB(dbDeployment db.TokenDeployment) TokenDeployment {
+	return TokenDeployment{
+		Blockchain:      dbDeployment.Blockchain,
+		ContractAddress: dbDeployment.ContractAddress,
+		Decimals:        dbDeployment.Decimals,
+		EscrowAddress:   dbDeployment.EscrowAddress,
+	}
+}
+
+// newDeploymentsFromDB converts db token deployments to service token deployments
+func newDeploymentsFromDB(dbDeployments []db.TokenDeployment) []TokenDeployment {
+	deployments := make([]TokenDeployment, len(dbDeployments))
+	for i, dbDeployment := range dbDeployments {
+		deployments[i] = newDeploymentFromDB(dbDeployment)
+	}
+	return deployments
+}
+
+// invalidateTokenCache removes cached data derived from a token's registry entry
+func invalidateTokenCache(ctx context.Context, symbol string) {
+	keys := append([]string{"tvl:" + symbol}, QuoteCacheKeys("valuation", symbol)...)
+	for _, key := range keys {
+		if err := cache.Delete(ctx, key); err != nil {
+			fmt.Printf("Warning: failed to invalidate cache key %s: %v\n", key, err)
+		}
+	}
+}
@@ -1,181 +1,308 @@
-package services
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"math"
-	"math/big"
-	"os"
-	"strings"
-	"time"
-
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
-	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
-)
-
-// TVLData represents TVL information for a token
-type TVLData struct {
-	TokenSymbol string    `json:"token_symbol"`
-	TVL         float64   `json:"tvl"`
-	LastUpdated time.Time `json:"last_updated"`
-}
-
-// CachedTVLData represents cached TVL data
-type CachedTVLData struct {
-	Data       TVLData    `json:"data"`
-	CachedAt   time.Time  `json:"cached_at"`
-	ExpiresAt  time.Time  `json:"expires_at"`
-}
-
-// TVLFetcher handles TVL data fetching from blockchain
-type TVLFetcher struct {
-	ethClient *ethclient.Client
-}
-
-// NewTVLFetcher creates a new TVL fetcher
-func NewTVLFetcher(rpcURL string) (*TVLFetcher, error) {
-	client, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
-	}
-
-	return &TVLFetcher{
-		ethClient: client,
-	}, nil
-}
-
-// ERC20 ABI for totalSupply function
-const erc20ABI = `[{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
-
-// FetchTVLFromContract fetches TVL by calling totalSupply on the token contract
-func (t *TVLFetcher) FetchTVLFromContract(ctx context.Context, contractAddress string, decimals int) (float64, error) {
-	// Parse the contract ABI
-	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse ABI: %w", err)
-	}
-
-	// Call totalSupply function
-	address := common.HexToAddress(contractAddress)
-	callData, err := parsedABI.Pack("totalSupply")
-	if err != nil {
-		return 0, fmt.Errorf("failed to pack call data: %w", err)
-	}
-
-	result, err := t.ethClient.CallContract(ctx, ethereum.CallMsg{
-		To:   &address,
-		Data: callData,
-	}, nil)
-	if err != nil {
-		return 0, fmt.Errorf("failed to call contract: %w", err)
-	}
-
-	// Unpack the result
-	outputs, err := parsedABI.Unpack("totalSupply", result)
-	if err != nil {
-		return 0, fmt.Errorf("failed to unpack result: %w", err)
-	}
-
-	if len(outputs) == 0 {
-		return 0, fmt.Errorf("no outputs from totalSupply call")
-	}
-
-	totalSupply, ok := outputs[0].(*big.Int)
-	if !ok {
-		return 0, fmt.Errorf("unexpected output type from totalSupply")
-	}
-
-	// Convert to float with proper decimal adjustment
-	tvlFloat := new(big.Float).SetInt(totalSupply)
-	decimalsMultiplier := new(big.Float).SetFloat64(math.Pow(10, float64(decimals)))
-	tvlFloat.Quo(tvlFloat, decimalsMultiplier)
-
-	tvl, _ := tvlFloat.Float64()
-	return tvl, nil
-}
-
-// GetCachedTVL retrieves TVL data from cache
-func GetCachedTVL(ctx context.Context, symbol string) (*TVLData, error) {
-	cacheKey := fmt.Sprintf("tvl:%s", symbol)
-
-	cachedData, err := cache.Get(ctx, cacheKey)
-	if err != nil {
-		return nil, nil // Cache miss
-	}
-
-	var cached CachedTVLData
-	if err := json.Unmarshal([]byte(cachedData), &cached); err != nil {
-		return nil, nil // Invalid cache data
-	}
-
-	// Check if cache is expired
-	if time.Now().After(cached.ExpiresAt) {
-		cache.Delete(ctx, cacheKey)
-		return nil, nil
-	}
-
-	return &cached.Data, nil
-}
-
-// SetCachedTVL stores TVL data in cache
-func SetCachedTVL(ctx context.Context, symbol string, data TVLData) error {
-	cacheDurationStr := os.Getenv("TVL_CACHE_DURATION")
-	cacheDuration := 5 * time.Minute
-	if cacheDurationStr != "" {
-		if parsed, err := time.ParseDuration(cacheDurationStr); err == nil {
-			cacheDuration = parsed
-		}
-	}
-
-	cacheKey := fmt.Sprintf("tvl:%s", symbol)
-
-	cached := CachedTVLData{
-		Data:      data,
-		CachedAt:  time.Now(),
-		ExpiresAt: time.Now().Add(cacheDuration),
-	}
-
-	cachedData, err := json.Marshal(cached)
-	if err != nil {
-		return fmt.Errorf("failed to marshal cache data: %w", err)
-	}
-
-	return cache.Set(ctx, cacheKey, string(cachedData), cacheDuration)
-}
-
-// FetchTVL fetches TVL data with caching
-func FetchTVL(ctx context.Context, symbol, contractAddress string, decimals int, rpcURL string) (float64, error) {
-	// Try to get from cache first
-	if cachedData, err := GetCachedTVL(ctx, symbol); err == nil && cachedData != nil {
-		return cachedData.TVL, nil
-	}
-
-	// Cache miss - fetch from blockchain
-	fetcher, err := NewTVLFetcher(rpcURL)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create TVL fetcher: %w", err)
-	}
-
-	tvl, err := fetcher.FetchTVLFromContract(ctx, contractAddress, decimals)
-	if err != nil {
-		return 0, fmt.Errorf("failed to fetch TVL from contract: %w", err)
-	}
-
-	// Cache the result
-	tvlData := TVLData{
-		TokenSymbol: symbol,
-		TVL:         tvl,
-		LastUpdated: time.Now(),
-	}
-
-	if cacheErr := SetCachedTVL(ctx, symbol, tvlData); cacheErr != nil {
-		// Log cache error but don't fail the request
-		fmt.Printf("Warning: failed to cache TVL for %s: %v\n", symbol, cacheErr)
-	}
-
-	return tvl, nil
-}
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/cache"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TVLData represents TVL information for a token
+type TVLData struct {
+	TokenSymbol string    `json:"token_symbol"`
+	Supply      float64   `json:"supply"`            // Raw token supply across all chains
+	TVL         float64   `json:"tvl"`               // Supply valued in ETH
+	TVLUSD      *float64  `json:"tvl_usd,omitempty"` // Supply valued in USD, omitted when no ETH/USD price is available
+	PricedWith  string    `json:"priced_with"`       // exchange_rate or market_price
+	LastUpdated time.Time `json:"last_updated"`
+
+	// Supply per chain; Supply is the sum of the chains that could be read
+	Chains []ChainTVL `json:"chains"`
+}
+
+// TVL pricing methods, reported in TVLData.PricedWith
+const (
+	PricedWithExchangeRate = "exchange_rate"
+	PricedWithMarketPrice  = "market_price"
+)
+
+// ChainTVL represents the supply of a token on a single chain
+type ChainTVL struct {
+	Blockchain      string  `json:"blockchain"`
+	ContractAddress string  `json:"contract_address"`
+	Canonical       bool    `json:"canonical"`
+	Supply          float64 `json:"supply"`
+	TVL             float64 `json:"tvl"`             // Supply valued in ETH
+	Error           string  `json:"error,omitempty"` // Set when the chain could not be read; excluded from TVL
+}
+
+// CachedTVLData represents cached TVL data
+type CachedTVLData struct {
+	Data       TVLData    `json:"data"`
+	CachedAt   time.Time  `json:"cached_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+// TVLFetcher handles TVL data fetching from blockchain
+type TVLFetcher struct {
+	ethClient *ethclient.Client
+}
+
+// NewTVLFetcher creates a new TVL fetcher
+func NewTVLFetcher(rpcURL string) (*TVLFetcher, error) {
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
+	}
+
+	return &TVLFetcher{
+		ethClient: client,
+	}, nil
+}
+
+// Close closes the underlying Ethereum client connection
+func (t *TVLFetcher) Close() {
+	t.ethClient.Close()
+}
+
+// ERC20 ABI for totalSupply, balanceOf and metadata functions
+const erc20ABI = `[{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+// FetchTotalSupply fetches the token supply by calling totalSupply on the token contract
+func (t *TVLFetcher) FetchTotalSupply(ctx context.Context, contractAddress string, decimals int) (float64, error) {
+	// Parse the contract ABI
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	// Call totalSupply function
+	address := common.HexToAddress(contractAddress)
+	callData, err := parsedABI.Pack("totalSupply")
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack call data: %w", err)
+	}
+
+	result, err := t.ethClient.CallContract(ctx, ethereum.CallMsg{
+		To:   &address,
+		Data: callData,
+	}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call contract: %w", err)
+	}
+
+	// Unpack the result
+	outputs, err := parsedABI.Unpack("totalSupply", result)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unpack result: %w", err)
+	}
+
+	if len(outputs) == 0 {
+		return 0, fmt.Errorf("no outputs from totalSupply call")
+	}
+
+	totalSupply, ok := outputs[0].(*big.Int)
+	if !ok {
+		return 0, fmt.Errorf("unexpected output type from totalSupply")
+	}
+
+	// Convert to float with proper decimal adjustment
+	supplyFloat := new(big.Float).SetInt(totalSupply)
+	decimalsMultiplier := new(big.Float).SetFloat64(math.Pow(10, float64(decimals)))
+	supplyFloat.Quo(supplyFloat, decimalsMultiplier)
+
+	supply, _ := supplyFloat.Float64()
+	return supply, nil
+}
+
+// GetCachedTVL retrieves TVL data from cache
+func GetCachedTVL(ctx context.Context, symbol string) (*TVLData, error) {
+	cacheKey := fmt.Sprintf("tvl:%s", symbol)
+
+	cachedData, err := cache.Get(ctx, cacheKey)
+	if err != nil {
+		return nil, nil // Cache miss
+	}
+
+	var cached CachedTVLData
+	if err := json.Unmarshal([]byte(cachedData), &cached); err != nil {
+		return nil, nil // Invalid cache data
+	}
+
+	// Check if cache is expired
+	if time.Now().After(cached.ExpiresAt) {
+		cache.Delete(ctx, cacheKey)
+		return nil, nil
+	}
+
+	return &cached.Data, nil
+}
+
+// SetCachedTVL stores TVL data in cache
+func SetCachedTVL(ctx context.Context, symbol string, data TVLData) error {
+	cacheDurationStr := os.Getenv("TVL_CACHE_DURATION")
+	cacheDuration := 5 * time.Minute
+	if cacheDurationStr != "" {
+		if parsed, err := time.ParseDuration(cacheDurationStr); err == nil {
+			cacheDuration = parsed
+		}
+	}
+
+	cacheKey := fmt.Sprintf("tvl:%s", symbol)
+
+	cached := CachedTVLData{
+		Data:      data,
+		CachedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(cacheDuration),
+	}
+
+	cachedData, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache data: %w", err)
+	}
+
+	return cache.Set(ctx, cacheKey, string(cachedData), cacheDuration)
+}
+
+// FetchTVL fetches TVL data with caching
+func FetchTVL(ctx context.Context, chains *ChainRegistry, token *Token) (*TVLData, error) {
+	// Try to get from cache first
+	if cachedData, err := GetCachedTVL(ctx, token.Symbol); err == nil && cachedData != nil {
+		return cachedData, nil
+	}
+
+	// Cache miss - fetch from blockchain
+	return RefreshTVL(ctx, chains, token)
+}
+
+// RefreshTVL reads the token supply on its canonical chain and every bridged deployment and updates the cache.
+// Canonical tokens locked in bridge escrows back bridged supply, so they are excluded from the canonical supply.
+// When a bridged read fails, the escrow balance backing that chain is counted in its place and the result is not
+// cached, so the next read retries the chain. The returned data holds supply only; ValuationService values it in
+// ETH and USD.
+func RefreshTVL(ctx context.Context, chains *ChainRegistry, token *Token) (*TVLData, error) {
+	canonical, locked, err := fetchCanonicalSupply(ctx, chains, token)
+	if err != nil {
+		return nil, err
+	}
+
+	tvlData := TVLData{
+		TokenSymbol: token.Symbol,
+		Supply:      canonical.Supply,
+		LastUpdated: time.Now(),
+		Chains:      []ChainTVL{*canonical},
+	}
+
+	// Bridged supply and failed reads per escrow, keyed by lowercase escrow address
+	bridged := map[string]float64{}
+	failedEscrows := map[string]bool{}
+	complete := true
+
+	for _, deployment := range token.Deployments {
+		chainTVL := ChainTVL{
+			Blockchain:      deployment.Blockchain,
+			ContractAddress: deployment.ContractAddress,
+		}
+		escrow := strings.ToLower(deployment.EscrowAddress)
+
+		supply, err := fetchChainSupply(ctx, chains, deployment.Blockchain, deployment.ContractAddress, deployment.Decimals)
+		if err != nil {
+			// Report the failed chain in the breakdown rather than failing the whole token
+			fmt.Printf("Warning: failed to fetch %s supply on %s: %v\n", token.Symbol, deployment.Blockchain, err)
+			chainTVL.Error = err.Error()
+			complete = false
+			if escrow != "" {
+				failedEscrows[escrow] = true
+			}
+		} else {
+			chainTVL.Supply = supply
+			if escrow != "" {
+				bridged[escrow] += supply
+			} else {
+				tvlData.Supply += supply
+			}
+		}
+
+		tvlData.Chains = append(tvlData.Chains, chainTVL)
+	}
+
+	for escrow, amount := range locked {
+		if failedEscrows[escrow] {
+			tvlData.Supply += amount
+		} else {
+			tvlData.Supply += bridged[escrow]
+		}
+	}
+
+	if !complete {
+		return &tvlData, nil
+	}
+
+	if cacheErr := SetCachedTVL(ctx, token.Symbol, tvlData); cacheErr != nil {
+		// Log cache error but don't fail the request
+		fmt.Printf("Warning: failed to cache TVL for %s: %v\n", token.Symbol, cacheErr)
+	}
+
+	return &tvlData, nil
+}
+
+// fetchCanonicalSupply reads the supply on the token's canonical chain, net of bridge escrow balances,
+// and returns the balance locked in each escrow keyed by lowercase address
+func fetchCanonicalSupply(ctx context.Context, chains *ChainRegistry, token *Token) (*ChainTVL, map[string]float64, error) {
+	fetcher, err := chains.Dial(token.Blockchain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create TVL fetcher: %w", err)
+	}
+	defer fetcher.Close()
+
+	supply, err := fetcher.FetchTotalSupply(ctx, token.ContractAddress, token.Decimals)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch total supply from contract: %w", err)
+	}
+
+	escrows := map[string]float64{}
+	for _, deployment := range token.Deployments {
+		escrow := strings.ToLower(deployment.EscrowAddress)
+		if escrow == "" {
+			continue
+		}
+		if _, fetched := escrows[escrow]; fetched {
+			continue
+		}
+
+		locked, err := fetcher.FetchBalanceOf(ctx, token.ContractAddress, escrow, token.Decimals)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch bridge escrow balance for %s: %w", deployment.Blockchain, err)
+		}
+		escrows[escrow] = locked
+		supply -= locked
+	}
+
+	return &ChainTVL{
+		Blockchain:      token.Blockchain,
+		ContractAddress: token.ContractAddress,
+		Canonical:       true,
+		Supply:          supply,
+	}, escrows, nil
+}
+
+// fetchChainSupply reads the total supply of a contract on the given chain
+func fetchChainSupply(ctx context.Context, chains *ChainRegistry, blockchain, contractAddress string, decimals int) (float64, error) {
+	fetcher, err := chains.Dial(blockchain)
+	if err != nil {
+		return 0, err
+	}
+	defer fetcher.Close()
+
+	return fetcher.FetchTotalSupply(ctx, contractAddress, decimals)
+}
@@ -1,86 +1,558 @@
-package services
-
-import (
-	"context"
-	"fmt"
-	"os"
-)
-
-// ValuationService handles valuation-related business logic
-type ValuationService struct {
-	coingeckoClient *CoinGeckoClient
-}
-
-// NewValuationService creates a new valuation service
-func NewValuationService(coingeckoClient *CoinGeckoClient) *ValuationService {
-	return &ValuationService{
-		coingeckoClient: coingeckoClient,
-	}
-}
-
-// GetTokenHistory retrieves price history for a token
-func (s *ValuationService) GetTokenHistory(ctx context.Context, symbol string) ([]PricePoint, error) {
-	priceHistory, err := s.coingeckoClient.GetPriceHistoryWithCache(ctx, symbol)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get price history for %s: %w", symbol, err)
-	}
-	return priceHistory, nil
-}
-
-// GetTokenValuation retrieves valuation metrics for a specific token
-func (s *ValuationService) GetTokenValuation(ctx context.Context, symbol string, token *Token) (*ValuationData, error) {
-	// Try to get from cache first
-	if cachedValuation, err := GetCachedValuation(ctx, symbol); err == nil && cachedValuation != nil {
-		return cachedValuation, nil
-	}
-
-	// Cache miss - compute valuation
-	priceHistory, err := s.GetTokenHistory(ctx, symbol)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get price history: %w", err)
-	}
-
-	// Fetch TVL data
-	rpcURL := os.Getenv("ETHEREUM_RPC_URL")
-	if rpcURL == "" {
-		rpcURL = "https://ethereum-rpc.publicnode.com"
-	}
-
-	tvl, err := FetchTVL(ctx, symbol, token.ContractAddress, token.Decimals, rpcURL)
-	if err != nil {
-		// Continue with TVL = 0 rather than failing completely
-		tvl = 0
-	}
-
-	// Calculate valuation
-	valuation, err := CalculateValuation(ctx, symbol, priceHistory, tvl)
-	if err != nil {
-		return nil, fmt.Errorf("failed to calculate valuation: %w", err)
-	}
-
-	// Cache the result
-	if cacheErr := SetCachedValuation(ctx, symbol, *valuation); cacheErr != nil {
-		// Log warning but don't fail
-		fmt.Printf("Warning: failed to cache valuation for %s: %v\n", symbol, cacheErr)
-	}
-
-	return valuation, nil
-}
-
-// GetAllTokenValuations retrieves valuation metrics for all tokens
-func (s *ValuationService) GetAllTokenValuations(ctx context.Context, tokens []Token) ([]ValuationData, error) {
-	var valuations []ValuationData
-
-	for _, token := range tokens {
-		valuation, err := s.GetTokenValuation(ctx, token.Symbol, &token)
-		if err != nil {
-			// Log error but continue with other tokens
-			fmt.Printf("Error getting valuation for %s: %v\n", token.Symbol, err)
-			continue
-		}
-
-		valuations = append(valuations, *valuation)
-	}
-
-	return valuations, nil
-}
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+)
+
+// ErrValuationNotReady is returned when no precomputed valuation exists yet for a token
+var ErrValuationNotReady = errors.New("valuation not computed yet")
+
+// ValuationService handles valuation-related business logic
+type ValuationService struct {
+	priceStore      *PriceHistoryStore
+	quoteStore      *ETHQuoteStore
+	exchangeRates   *ExchangeRateRegistry
+	chains          *ChainRegistry
+	coingeckoClient *CoinGeckoClient
+	precomputed     bool
+}
+
+// NewValuationService creates a new valuation service
+func NewValuationService(priceStore *PriceHistoryStore, quoteStore *ETHQuoteStore, exchangeRates *ExchangeRateRegistry, chains *ChainRegistry, coingeckoClient *CoinGeckoClient) *ValuationService {
+	return &ValuationService{
+		priceStore:      priceStore,
+		quoteStore:      quoteStore,
+		exchangeRates:   exchangeRates,
+		chains:          chains,
+		coingeckoClient: coingeckoClient,
+	}
+}
+
+// UsePrecomputedData makes read paths serve only data produced by background refreshes
+// instead of calling CoinGecko and the RPC node on a cache miss
+func (s *ValuationService) UsePrecomputedData(enabled bool) {
+	s.precomputed = enabled
+}
+
+// GetTokenHistory retrieves price history for a token over the range, interval and quote currency of a query.
+// Daily and weekly history is served from the stored daily prices; unless data is precomputed, ranges older
+// than the store are backfilled from the price source once. Hourly history is fetched from the price source
+// and cached.
+func (s *ValuationService) GetTokenHistory(ctx context.Context, symbol string, query HistoryQuery) ([]PricePoint, error) {
+	if query.Interval == IntervalHourly {
+		priceHistory, err := s.priceStore.GetHourlyHistory(ctx, symbol, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hourly price history for %s: %w", symbol, err)
+		}
+		return downsamplePricePoints(priceHistory, IntervalHourly), nil
+	}
+
+	var priceHistory []PricePoint
+	var err error
+	if query.Quote == QuoteETH {
+		priceHistory, err = s.getPriceHistory(ctx, symbol, query.From)
+	} else {
+		priceHistory, err = s.getConvertedPriceHistory(ctx, symbol, query.Quote, query.From)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return downsamplePricePoints(filterPricePoints(priceHistory, query.From, query.To), query.Interval), nil
+}
+
+// getPriceHistory retrieves the full stored ETH price history of a token from the price history store,
+// backfilling it back to from unless data is precomputed
+func (s *ValuationService) getPriceHistory(ctx context.Context, symbol string, from time.Time) ([]PricePoint, error) {
+	getPriceHistory := s.priceStore.GetPriceHistory
+	if s.precomputed {
+		getPriceHistory = s.priceStore.GetStoredPriceHistory
+	}
+
+	priceHistory, err := getPriceHistory(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history for %s: %w", symbol, err)
+	}
+
+	// Precomputed data is only extended by the scheduler, up to the configured backfill window
+	if !s.precomputed && startsAfter(priceHistory, from) {
+		if err := s.priceStore.Backfill(ctx, symbol, backfillStart(from)); err != nil {
+			fmt.Printf("Warning: failed to backfill price history for %s: %v\n", symbol, err)
+		} else if priceHistory, err = getPriceHistory(ctx, symbol); err != nil {
+			return nil, fmt.Errorf("failed to get price history for %s: %w", symbol, err)
+		}
+	}
+
+	return priceHistory, nil
+}
+
+// getConvertedPriceHistory retrieves the full price history of a token in a non-ETH quote currency.
+// Prices are converted from the ETH prices through the stored ETH price series of the quote and cached.
+func (s *ValuationService) getConvertedPriceHistory(ctx context.Context, symbol, quote string, from time.Time) ([]PricePoint, error) {
+	// Try to get from cache first; a copy starting after from is rebuilt when the store may be backfilled
+	if cachedData, err := GetCachedPriceHistory(ctx, symbol, quote); err == nil && cachedData != nil {
+		if s.precomputed || !startsAfter(cachedData, from) {
+			return cachedData, nil
+		}
+	}
+
+	priceHistory, err := s.getPriceHistory(ctx, symbol, from)
+	if err != nil {
+		return nil, err
+	}
+
+	ethQuotes, err := s.getQuoteHistory(ctx, quote, from)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := convertPriceHistory(priceHistory, ethQuotes)
+
+	// Cache the result
+	if cacheErr := SetCachedPriceHistory(ctx, symbol, quote, converted); cacheErr != nil {
+		fmt.Printf("Warning: failed to cache price history for %s in %s: %v\n", symbol, quote, cacheErr)
+	}
+
+	return converted, nil
+}
+
+// getQuoteHistory retrieves the stored ETH price series of a quote currency, backfilling it back to from
+// unless data is precomputed
+func (s *ValuationService) getQuoteHistory(ctx context.Context, quote string, from time.Time) ([]PricePoint, error) {
+	ethQuotes, err := s.quoteStore.GetQuoteHistory(ctx, quote, !s.precomputed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ETH price history in %s: %w", quote, err)
+	}
+	if !s.precomputed && startsAfter(ethQuotes, from) {
+		if err := s.quoteStore.Backfill(ctx, quote, backfillStart(from)); err != nil {
+			fmt.Printf("Warning: failed to backfill ETH price history in %s: %v\n", quote, err)
+		} else if ethQuotes, err = s.quoteStore.GetQuoteHistory(ctx, quote, false); err != nil {
+			return nil, fmt.Errorf("failed to get ETH price history in %s: %w", quote, err)
+		}
+	}
+
+	return ethQuotes, nil
+}
+
+// GetTokenValuation retrieves valuation metrics for a specific token in a quote currency with the APR
+// computed by the given methodology. Only valuations with the default methodology are cached and stored;
+// other methodologies are recomputed on request from the default valuation and the price history.
+func (s *ValuationService) GetTokenValuation(ctx context.Context, symbol string, token *Token, quote, aprMethod string) (*ValuationData, error) {
+	if aprMethod != DefaultAPRMethod {
+		valuation, err := s.GetTokenValuation(ctx, symbol, token, QuoteETH, DefaultAPRMethod)
+		if err != nil {
+			return nil, err
+		}
+		valuation, err = s.withAPRMethod(ctx, *valuation, aprMethod)
+		if err != nil {
+			return nil, err
+		}
+		if quote == QuoteETH {
+			return valuation, nil
+		}
+		return s.convertValuation(ctx, *valuation, quote)
+	}
+
+	// Try to get from cache first
+	if cachedValuation, err := GetCachedValuation(ctx, symbol, quote); err == nil && cachedValuation != nil {
+		return cachedValuation, nil
+	}
+
+	// Valuations are computed in ETH and converted to other quotes
+	if quote != QuoteETH {
+		valuation, err := s.GetTokenValuation(ctx, symbol, token, QuoteETH, aprMethod)
+		if err != nil {
+			return nil, err
+		}
+		valuation, err = s.convertValuation(ctx, *valuation, quote)
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the result
+		if cacheErr := SetCachedValuation(ctx, symbol, quote, *valuation); cacheErr != nil {
+			fmt.Printf("Warning: failed to cache valuation for %s in %s: %v\n", symbol, quote, cacheErr)
+		}
+		return valuation, nil
+	}
+
+	// Cache miss - serve the latest stored snapshot when valuations are precomputed
+	if s.precomputed {
+		return s.getLatestValuation(ctx, symbol)
+	}
+
+	return s.RefreshTokenValuation(ctx, token)
+}
+
+// RefreshTokenValuation computes fresh valuation metrics for a token, caching and storing the result
+func (s *ValuationService) RefreshTokenValuation(ctx context.Context, token *Token) (*ValuationData, error) {
+	symbol := token.Symbol
+
+	priceHistory, err := s.GetTokenHistory(ctx, symbol, DefaultHistoryQuery())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	// Read the protocol exchange rate when the token has an adapter; it also values TVL in ETH
+	var exchangeRate *ExchangeRateData
+	if _, exists := s.exchangeRates.Get(symbol); exists {
+		exchangeRate, err = s.GetTokenExchangeRate(ctx, token)
+		if err != nil {
+			fmt.Printf("Warning: failed to fetch exchange rate for %s: %v\n", symbol, err)
+		}
+	}
+
+	// Fetch TVL data
+	var tvlData *TVLData
+	supplyData, err := FetchTVL(ctx, s.chains, token)
+	if err == nil {
+		tvlData, err = s.valueTVL(ctx, *supplyData, exchangeRate, latestPrice(priceHistory))
+	}
+	if err != nil {
+		// Continue with TVL = 0 rather than failing completely
+		fmt.Printf("Warning: failed to fetch TVL for %s: %v\n", symbol, err)
+		tvlData = nil
+	}
+
+	var tvl float64
+	if tvlData != nil {
+		tvl = tvlData.TVL
+	}
+
+	var protocolAPR *float64
+	if exchangeRate != nil {
+		protocolAPR = exchangeRate.ProtocolAPR
+	}
+
+	// Calculate valuation
+	calculator, err := GetAPRCalculator(DefaultAPRMethod)
+	if err != nil {
+		return nil, err
+	}
+	valuation, err := CalculateValuation(ctx, symbol, priceHistory, tvl, calculator, protocolAPR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate valuation: %w", err)
+	}
+	if tvlData != nil {
+		valuation.Supply = tvlData.Supply
+		valuation.TVLUSD = tvlData.TVLUSD
+		valuation.TVLChains = tvlData.Chains
+	}
+
+	// Report the protocol-rate APR alongside the market-price APR when the token has an adapter
+	if exchangeRate != nil {
+		valuation.ExchangeRate = &exchangeRate.Rate
+		valuation.ProtocolAPR = exchangeRate.ProtocolAPR
+		if premium, ok := CalculatePremiumBps(valuation.Price, exchangeRate.Rate); ok {
+			valuation.PremiumBps = &premium
+		}
+	}
+
+	// Trading volume feeds the liquidity component of composite scores
+	if volume, err := FetchTokenVolume(ctx, s.coingeckoClient, symbol); err != nil {
+		fmt.Printf("Warning: failed to fetch trading volume for %s: %v\n", symbol, err)
+	} else {
+		valuation.Volume24h = &volume
+	}
+
+	// Cache the result
+	if cacheErr := SetCachedValuation(ctx, symbol, QuoteETH, *valuation); cacheErr != nil {
+		// Log warning but don't fail
+		fmt.Printf("Warning: failed to cache valuation for %s: %v\n", symbol, cacheErr)
+	}
+	invalidateConvertedCaches(ctx, "valuation", symbol)
+
+	// Keep a timestamped snapshot so the valuation history can be charted later
+	if storeErr := saveValuationSnapshot(*valuation); storeErr != nil {
+		fmt.Printf("Warning: failed to store valuation snapshot for %s: %v\n", symbol, storeErr)
+	}
+
+	return valuation, nil
+}
+
+// convertValuation converts the price and TVL of an ETH valuation to a quote currency using the latest
+// stored ETH price in that currency, and caches the result. APR, stability, remarks and the exchange
+// rate stay relative to ETH.
+func (s *ValuationService) convertValuation(ctx context.Context, valuation ValuationData, quote string) (*ValuationData, error) {
+	ethQuotes, err := s.quoteStore.GetQuoteHistory(ctx, quote, !s.precomputed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ETH price history in %s: %w", quote, err)
+	}
+
+	ethPrice := latestPrice(ethQuotes)
+	if ethPrice <= 0 {
+		return nil, fmt.Errorf("no ETH price available in %s", quote)
+	}
+
+	valuation.Quote = quote
+	valuation.Price *= ethPrice
+	valuation.TVL *= ethPrice
+	valuation.TVLChains = append([]ChainTVL{}, valuation.TVLChains...)
+	for i := range valuation.TVLChains {
+		valuation.TVLChains[i].TVL *= ethPrice
+	}
+
+	return &valuation, nil
+}
+
+// withAPRMethod recomputes the APR and the remarks derived from it for an ETH valuation with another methodology
+func (s *ValuationService) withAPRMethod(ctx context.Context, valuation ValuationData, aprMethod string) (*ValuationData, error) {
+	calculator, err := GetAPRCalculator(aprMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	priceHistory, err := s.GetTokenHistory(ctx, valuation.TokenSymbol, DefaultHistoryQuery())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	recomputed, err := CalculateValuation(ctx, valuation.TokenSymbol, priceHistory, valuation.TVL, calculator, valuation.ProtocolAPR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate valuation: %w", err)
+	}
+
+	valuation.APR = recomputed.APR
+	valuation.APRMethod = recomputed.APRMethod
+	valuation.Remarks = recomputed.Remarks
+	return &valuation, nil
+}
+
+// RefreshQuoteHistories syncs the stored ETH price series of every non-ETH quote currency
+func (s *ValuationService) RefreshQuoteHistories(ctx context.Context) error {
+	var errs []error
+	for _, quote := range Quotes {
+		if quote == QuoteETH {
+			continue
+		}
+		if err := s.quoteStore.Refresh(ctx, quote); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", quote, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RefreshTokenPriceHistory syncs the stored price history for a token and refreshes its cache
+func (s *ValuationService) RefreshTokenPriceHistory(ctx context.Context, token *Token) error {
+	return s.priceStore.Refresh(ctx, token.Symbol)
+}
+
+// GetTokenExchangeRate retrieves the on-chain protocol exchange rate for a token.
+// ErrNoExchangeRateAdapter is returned for tokens without a protocol adapter.
+func (s *ValuationService) GetTokenExchangeRate(ctx context.Context, token *Token) (*ExchangeRateData, error) {
+	rpcURL, err := s.chains.RPCURL(token.Blockchain)
+	if err != nil {
+		return nil, err
+	}
+	return FetchExchangeRate(ctx, s.exchangeRates, token.Symbol, token.ContractAddress, token.Decimals, rpcURL)
+}
+
+// GetTokenTVL retrieves the TVL of a token in ETH and USD with its per-chain breakdown
+func (s *ValuationService) GetTokenTVL(ctx context.Context, token *Token) (*TVLData, error) {
+	supplyData, err := FetchTVL(ctx, s.chains, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var exchangeRate *ExchangeRateData
+	var marketPrice float64
+	if _, exists := s.exchangeRates.Get(token.Symbol); exists {
+		exchangeRate, err = s.GetTokenExchangeRate(ctx, token)
+		if err != nil {
+			fmt.Printf("Warning: failed to fetch exchange rate for %s: %v\n", token.Symbol, err)
+		}
+	}
+	if exchangeRate == nil {
+		priceHistory, err := s.GetTokenHistory(ctx, token.Symbol, DefaultHistoryQuery())
+		if err != nil {
+			return nil, err
+		}
+		marketPrice = latestPrice(priceHistory)
+	}
+
+	return s.valueTVL(ctx, *supplyData, exchangeRate, marketPrice)
+}
+
+// valueTVL values token supply in ETH using the protocol exchange rate when available and the
+// market price otherwise, and in USD using the current ETH/USD price
+func (s *ValuationService) valueTVL(ctx context.Context, tvlData TVLData, exchangeRate *ExchangeRateData, marketPrice float64) (*TVLData, error) {
+	ethPerToken := marketPrice
+	tvlData.PricedWith = PricedWithMarketPrice
+	if exchangeRate != nil && exchangeRate.Rate > 0 {
+		ethPerToken = exchangeRate.Rate
+		tvlData.PricedWith = PricedWithExchangeRate
+	}
+	if ethPerToken <= 0 {
+		return nil, fmt.Errorf("no ETH price available for %s", tvlData.TokenSymbol)
+	}
+
+	tvlData.TVL = tvlData.Supply * ethPerToken
+	tvlData.Chains = append([]ChainTVL{}, tvlData.Chains...)
+	for i := range tvlData.Chains {
+		tvlData.Chains[i].TVL = tvlData.Chains[i].Supply * ethPerToken
+	}
+
+	ethUSD, err := FetchETHPrice(ctx, s.coingeckoClient, "usd")
+	if err != nil {
+		// Keep the ETH-denominated TVL when USD pricing is unavailable
+		fmt.Printf("Warning: failed to value TVL for %s in USD: %v\n", tvlData.TokenSymbol, err)
+	} else {
+		tvlUSD := tvlData.TVL * ethUSD
+		tvlData.TVLUSD = &tvlUSD
+	}
+
+	return &tvlData, nil
+}
+
+// latestPrice returns the price of the most recent point in a price history
+func latestPrice(priceHistory []PricePoint) float64 {
+	var latest PricePoint
+	for _, point := range priceHistory {
+		if point.Timestamp > latest.Timestamp {
+			latest = point
+		}
+	}
+	return latest.Price
+}
+
+// RefreshTokenTVL fetches the current TVL for a token from every chain it is deployed on and refreshes its cache
+func (s *ValuationService) RefreshTokenTVL(ctx context.Context, token *Token) error {
+	_, err := RefreshTVL(ctx, s.chains, token)
+	return err
+}
+
+// getLatestValuation returns the most recent stored valuation snapshot for a token
+func (s *ValuationService) getLatestValuation(ctx context.Context, symbol string) (*ValuationData, error) {
+	snapshot, err := db.GetLatestValuationSnapshot(symbol)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrValuationNotReady, symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest valuation snapshot for %s: %w", symbol, err)
+	}
+
+	valuation := newValuationFromSnapshot(symbol, *snapshot)
+	return &valuation, nil
+}
+
+// GetValuationHistory retrieves the stored valuation snapshots for a token between from and to
+func (s *ValuationService) GetValuationHistory(ctx context.Context, symbol string, from, to time.Time) ([]ValuationData, error) {
+	snapshots, err := db.GetValuationSnapshots(symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get valuation snapshots for %s: %w", symbol, err)
+	}
+
+	history := make([]ValuationData, len(snapshots))
+	for i, snapshot := range snapshots {
+		history[i] = newValuationFromSnapshot(symbol, snapshot)
+	}
+
+	return history, nil
+}
+
+// GetPremiumHistory retrieves the market premium to NAV for a token between from and to.
+// The series is derived from stored valuation snapshots, which record both the market price
+// and the protocol exchange rate. ErrNoExchangeRateAdapter is returned for tokens without an adapter.
+func (s *ValuationService) GetPremiumHistory(ctx context.Context, symbol string, from, to time.Time) ([]PremiumPoint, error) {
+	if _, exists := s.exchangeRates.Get(symbol); !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNoExchangeRateAdapter, symbol)
+	}
+
+	snapshots, err := db.GetValuationSnapshots(symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get valuation snapshots for %s: %w", symbol, err)
+	}
+
+	history := []PremiumPoint{}
+	for _, snapshot := range snapshots {
+		if snapshot.ExchangeRate == nil {
+			continue
+		}
+		premium, ok := CalculatePremiumBps(snapshot.Price, *snapshot.ExchangeRate)
+		if !ok {
+			continue
+		}
+		history = append(history, PremiumPoint{
+			Timestamp:    snapshot.ComputedAt.UnixMilli(),
+			Price:        snapshot.Price,
+			ExchangeRate: *snapshot.ExchangeRate,
+			PremiumBps:   premium,
+		})
+	}
+
+	return history, nil
+}
+
+// newValuationFromSnapshot converts a stored valuation snapshot to valuation data
+func newValuationFromSnapshot(symbol string, snapshot db.ValuationSnapshot) ValuationData {
+	var premiumBps *float64
+	if snapshot.ExchangeRate != nil {
+		if premium, ok := CalculatePremiumBps(snapshot.Price, *snapshot.ExchangeRate); ok {
+			premiumBps = &premium
+		}
+	}
+
+	return ValuationData{
+		TokenSymbol:  symbol,
+		Price:        snapshot.Price,
+		Quote:        QuoteETH,
+		APR:          snapshot.APR,
+		APRMethod:    snapshot.APRMethod,
+		Stability:    snapshot.Stability,
+		TVL:          snapshot.TVL,
+		TVLUSD:       snapshot.TVLUSD,
+		Supply:       snapshot.Supply,
+		Volume24h:    snapshot.Volume24h,
+		Remarks:      snapshot.Remarks,
+		LastUpdated:  snapshot.ComputedAt,
+		ExchangeRate: snapshot.ExchangeRate,
+		ProtocolAPR:  snapshot.ProtocolAPR,
+		PremiumBps:   premiumBps,
+	}
+}
+
+// saveValuationSnapshot stores a computed valuation as a snapshot in the database
+func saveValuationSnapshot(valuation ValuationData) error {
+	return db.InsertValuationSnapshot(valuation.TokenSymbol, db.ValuationSnapshot{
+		Price:        valuation.Price,
+		APR:          valuation.APR,
+		APRMethod:    valuation.APRMethod,
+		Stability:    valuation.Stability,
+		TVL:          valuation.TVL,
+		TVLUSD:       valuation.TVLUSD,
+		Supply:       valuation.Supply,
+		Volume24h:    valuation.Volume24h,
+		Remarks:      valuation.Remarks,
+		ComputedAt:   valuation.LastUpdated,
+		ExchangeRate: valuation.ExchangeRate,
+		ProtocolAPR:  valuation.ProtocolAPR,
+	})
+}
+
+// GetAllTokenValuations retrieves valuation metrics for all tokens in a quote currency. Tokens without a
+// protocol APR fall back to the default methodology under protocol_rate, as reported by their apr_method.
+func (s *ValuationService) GetAllTokenValuations(ctx context.Context, tokens []Token, quote, aprMethod string) ([]ValuationData, error) {
+	var valuations []ValuationData
+
+	for _, token := range tokens {
+		valuation, err := s.GetTokenValuation(ctx, token.Symbol, &token, quote, aprMethod)
+		if errors.Is(err, ErrNoExchangeRateAdapter) && aprMethod != DefaultAPRMethod {
+			valuation, err = s.GetTokenValuation(ctx, token.Symbol, &token, quote, DefaultAPRMethod)
+		}
+		if err != nil {
+			// Log error but continue with other tokens
+			fmt.Printf("Error getting valuation for %s: %v\n", token.Symbol, err)
+			continue
+		}
+
+		valuations = append(valuations, *valuation)
+	}
+
+	return valuations, nil
+}
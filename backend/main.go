@@ -1,53 +1,157 @@
-// Package main ETH Staking Analytics Backend API
-//
-// This is the backend API for the ETH Staking Analytics application.
-// It provides endpoints for retrieving LST token data, price history, and valuation metrics.
-//
-// Terms Of Service: https://github.com/Haxsen/HxnETHstakingAnalyticsApp
-//
-// Schemes: http, https
-// Host: localhost:8080
-// BasePath: /api
-// Version: 1.0.0
-//
-// Consumes:
-// - application/json
-//
-// Produces:
-// - application/json
-//
-// swagger:meta
-package main
-
-import (
-	"log"
-	"os"
-
-	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/server"
-	"github.com/joho/godotenv"
-)
-
-func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
-	}
-
-	// Create server configuration
-	cfg := &server.Config{
-		Port:               os.Getenv("PORT"),
-		CORSAllowedOrigins: os.Getenv("CORS_ALLOWED_ORIGINS"),
-		CoinGeckoAPIKey:    os.Getenv("COINGECKO_API_KEY"),
-		EthereumRPCURL:     os.Getenv("ETHEREUM_RPC_URL"),
-	}
-
-	// Create and start server
-	srv, err := server.NewServer(cfg)
-	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
-	}
-	defer srv.Close()
-
-	// Start the server (this blocks)
-	log.Fatal(srv.Start())
-}
+// Package main ETH Staking Analytics Backend API
+//
+// This is the backend API for the ETH Staking Analytics application.
+// It provides endpoints for retrieving LST token data, price history, and valuation metrics.
+//
+// Terms Of Service: https://github.com/Haxsen/HxnETHstakingAnalyticsApp
+//
+// Schemes: http, https
+// Host: localhost:8080
+// BasePath: /api
+// Version: 1.0.0
+//
+// Consumes:
+// - application/json
+//
+// Produces:
+// - application/json
+//
+// swagger:meta
+//
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Admin API token, sent as "Bearer <token>"
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/db"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/scheduler"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/server"
+	"github.com/Haxsen/HxnETHstakingAnalyticsApp/backend/internal/services"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	// Run database migrations without starting the server: migrate [up|down [steps]|status]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Create server configuration
+	cfg := &server.Config{
+		Port:                os.Getenv("PORT"),
+		CORSAllowedOrigins:  os.Getenv("CORS_ALLOWED_ORIGINS"),
+		CoinGeckoAPIKey:     os.Getenv("COINGECKO_API_KEY"),
+		RPCURLs:             rpcURLsFromEnv(),
+		AdminAPIToken:       os.Getenv("ADMIN_API_TOKEN"),
+		CryptoCompareAPIKey: os.Getenv("CRYPTOCOMPARE_API_KEY"),
+		PriceSources:        os.Getenv("PRICE_SOURCES"),
+		SchedulerEnabled:    os.Getenv("SCHEDULER_ENABLED") != "false",
+		Scheduler: scheduler.Config{
+			PriceHistoryInterval: durationFromEnv("PRICE_HISTORY_REFRESH_INTERVAL", time.Hour),
+			TVLInterval:          durationFromEnv("TVL_REFRESH_INTERVAL", 5*time.Minute),
+			ValuationInterval:    durationFromEnv("VALUATION_REFRESH_INTERVAL", 10*time.Minute),
+		},
+	}
+
+	// Create and start server
+	srv, err := server.NewServer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	// Start the server (this blocks)
+	log.Fatal(srv.Start())
+}
+
+// rpcURLsFromEnv reads the RPC endpoint of each known chain from <CHAIN>_RPC_URL (e.g. ARBITRUM_RPC_URL)
+// and of any additional chains from CHAIN_RPC_URLS, formatted as "polygon=https://...,bsc=https://..."
+func rpcURLsFromEnv() map[string]string {
+	rpcURLs := map[string]string{}
+	for chain := range services.DefaultRPCURLs {
+		if rpcURL := os.Getenv(strings.ToUpper(chain) + "_RPC_URL"); rpcURL != "" {
+			rpcURLs[chain] = rpcURL
+		}
+	}
+
+	for _, entry := range strings.Split(os.Getenv("CHAIN_RPC_URLS"), ",") {
+		chain, rpcURL, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found || chain == "" || rpcURL == "" {
+			continue
+		}
+		rpcURLs[strings.ToLower(chain)] = rpcURL
+	}
+
+	return rpcURLs
+}
+
+// runMigrate handles the migrate subcommand
+func runMigrate(args []string) error {
+	if err := db.Connect(); err != nil {
+		return err
+	}
+	defer db.CloseDB()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		return db.MigrateUp()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+			steps = parsed
+		}
+		return db.MigrateDown(steps)
+	case "status":
+		statuses, err := db.GetMigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q (expected up, down or status)", command)
+	}
+}
+
+// durationFromEnv parses a Go duration from an environment variable, falling back to the default
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("Invalid %s %q, using default %s", name, value, defaultValue)
+	}
+	return defaultValue
+}
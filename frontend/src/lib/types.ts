@@ -1,87 +1,174 @@
-// API Response Types
-
-export interface Token {
-  id: number
-  symbol: string
-  name: string
-  contract_address: string
-  decimals: number
-  blockchain: string
-  is_active: boolean
-  created_at: string
-  updated_at: string
-}
-
-export interface TokensResponse {
-  tokens: Token[]
-  count: number
-}
-
-export interface PricePoint {
-  timestamp: number
-  price: number
-}
-
-export interface TokenHistoryResponse {
-  token_symbol: string
-  price_history: PricePoint[]
-  count: number
-}
-
-export interface ValuationData {
-  token_symbol: string
-  price: number
-  apr: number
-  stability: number
-  tvl: number
-  remarks: string
-  last_updated: string
-}
-
-export interface ValuationsResponse {
-  valuations: ValuationData[]
-  count: number
-}
-
-export interface TokenValuationResponse extends ValuationData {}
-
-export interface CacheRefreshResponse {
-  message: string
-}
-
-// Chart Data Types
-export interface ChartDataPoint {
-  timestamp: number
-  date: string
-  [tokenSymbol: string]: number | string
-}
-
-export interface ChartSeries {
-  name: string
-  type: 'line'
-  data: (number | null)[]
-  smooth: boolean
-  symbol: 'none'
-  lineStyle: {
-    width: number
-    type?: 'solid' | 'dashed'
-  }
-  itemStyle?: {
-    color: string
-  }
-}
-
-// UI State Types
-export interface LoadingState {
-  tokens: boolean
-  history: boolean
-  valuations: boolean
-  [key: string]: boolean
-}
-
-export interface ErrorState {
-  tokens?: string
-  history?: string
-  valuations?: string
-  [key: string]: string | undefined
-}
+// API Response Types
+
+export interface Token {
+  id: number
+  symbol: string
+  name: string
+  contract_address: string
+  decimals: number
+  blockchain: string
+  is_active: boolean
+  is_custodial: boolean
+  created_at: string
+  updated_at: string
+}
+
+export interface TokensResponse {
+  tokens: Token[]
+  count: number
+}
+
+export interface PricePoint {
+  timestamp: number
+  price: number
+}
+
+export interface TokenHistoryResponse {
+  token_symbol: string
+  quote: string
+  interval: 'hourly' | 'daily' | 'weekly'
+  from: string
+  to: string
+  price_history: PricePoint[]
+  count: number
+}
+
+export interface Candle {
+  timestamp: number
+  open: number | null
+  high: number | null
+  low: number | null
+  close: number | null
+  count: number
+  partial: boolean
+}
+
+export interface CandlesResponse {
+  token_symbol: string
+  quote: string
+  resolution: '1h' | '1d' | '1w'
+  from: string
+  to: string
+  candles: Candle[]
+  count: number
+}
+
+export interface ValuationData {
+  token_symbol: string
+  price: number
+  quote: string
+  apr: number
+  apr_method: string
+  stability: number
+  tvl: number // ETH
+  tvl_usd?: number
+  supply: number
+  volume_24h?: number // USD
+  remarks: string
+  last_updated: string
+  score?: CompositeScore
+}
+
+export interface ScoreComponent {
+  name: 'apr' | 'stability' | 'tvl' | 'valuation' | 'depeg' | 'liquidity'
+  weight: number
+  value: number | null
+  normalized: number | null
+  contribution: number
+}
+
+export interface CompositeScore {
+  profile: string
+  score: number
+  components: ScoreComponent[]
+}
+
+export interface ValuationsResponse {
+  quote: string
+  apr_method: string
+  profile?: string
+  valuations: ValuationData[]
+  count: number
+}
+
+export interface TokenValuationResponse extends ValuationData {}
+
+export interface ComparisonSeries {
+  symbol: string
+  prices: number[]
+  rebased: number[] // 100 at the first timestamp
+  filled: number // Intervals carried forward from an earlier price
+}
+
+export interface PriceRatio {
+  symbol: string
+  priced_in: string
+  values: number[]
+}
+
+export interface ComparisonResponse {
+  symbols: string[]
+  quote: string
+  interval: string
+  from: string
+  to: string
+  timestamps: number[] // Shared by every series and ratio
+  series: ComparisonSeries[]
+  ratios: PriceRatio[]
+  valuations: ValuationData[]
+}
+
+export type RefreshJobStatus = 'pending' | 'running' | 'completed' | 'failed'
+
+export interface RefreshTokenProgress {
+  symbol: string
+  status: RefreshJobStatus
+  completed: string[]
+  errors?: Record<string, string>
+}
+
+export interface CacheRefreshResponse {
+  job_id: string
+  status: RefreshJobStatus
+  kinds: string[]
+  tokens: RefreshTokenProgress[]
+  created_at: string
+  finished_at?: string
+}
+
+// Chart Data Types
+export interface ChartDataPoint {
+  timestamp: number
+  date: string
+  [tokenSymbol: string]: number | string
+}
+
+export interface ChartSeries {
+  name: string
+  type: 'line'
+  data: (number | null)[]
+  smooth: boolean
+  symbol: 'none'
+  lineStyle: {
+    width: number
+    type?: 'solid' | 'dashed'
+  }
+  itemStyle?: {
+    color: string
+  }
+}
+
+// UI State Types
+export interface LoadingState {
+  tokens: boolean
+  history: boolean
+  valuations: boolean
+  [key: string]: boolean
+}
+
+export interface ErrorState {
+  tokens?: string
+  history?: string
+  valuations?: string
+  [key: string]: string | undefined
+}